@@ -1,15 +1,15 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/lancher-dev/lancher/internal/cli"
+	"github.com/lancher-dev/lancher/internal/cli/shared"
 )
 
 func main() {
 	if err := cli.Run(os.Args[1:]); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		shared.PrintError(err)
 		os.Exit(1)
 	}
 }
@@ -5,8 +5,9 @@ go 1.24.0
 toolchain go1.24.10
 
 require (
+	github.com/klauspost/compress v1.18.0
+	github.com/ulikunitz/xz v0.5.9
+	golang.org/x/sys v0.38.0
 	golang.org/x/term v0.37.0
 	gopkg.in/yaml.v3 v3.0.1
 )
-
-require golang.org/x/sys v0.38.0 // indirect
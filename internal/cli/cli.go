@@ -2,15 +2,22 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/lancher-dev/lancher/internal/cli/commands"
 	"github.com/lancher-dev/lancher/internal/cli/shared"
 	"github.com/lancher-dev/lancher/internal/cli/template"
+	"github.com/lancher-dev/lancher/internal/storage"
 	"github.com/lancher-dev/lancher/internal/version"
 )
 
 // Run executes the CLI command based on arguments
 func Run(args []string) error {
+	args, err := parseGlobalFlags(args)
+	if err != nil {
+		return err
+	}
+
 	if len(args) == 0 {
 		return runHelp()
 	}
@@ -50,6 +57,36 @@ func Run(args []string) error {
 				return template.RunUpdateHelp()
 			}
 			return template.RunUpdate(subArgs)
+		case "search":
+			// Check for help flag
+			if len(subArgs) > 0 && (subArgs[0] == "help" || subArgs[0] == "-h" || subArgs[0] == "--help") {
+				return template.RunSearchHelp()
+			}
+			return template.RunSearch(subArgs)
+		case "info":
+			// Check for help flag
+			if len(subArgs) > 0 && (subArgs[0] == "help" || subArgs[0] == "-h" || subArgs[0] == "--help") {
+				return template.RunInfoHelp()
+			}
+			return template.RunInfo(subArgs)
+		case "ls-files":
+			// Check for help flag
+			if len(subArgs) > 0 && (subArgs[0] == "help" || subArgs[0] == "-h" || subArgs[0] == "--help") {
+				return template.RunLsFilesHelp()
+			}
+			return template.RunLsFiles(subArgs)
+		case "versions":
+			// Check for help flag
+			if len(subArgs) > 0 && (subArgs[0] == "help" || subArgs[0] == "-h" || subArgs[0] == "--help") {
+				return template.RunVersionsHelp()
+			}
+			return template.RunVersions(subArgs)
+		case "rollback":
+			// Check for help flag
+			if len(subArgs) > 0 && (subArgs[0] == "help" || subArgs[0] == "-h" || subArgs[0] == "--help") {
+				return template.RunRollbackHelp()
+			}
+			return template.RunRollback(subArgs)
 		case "remove", "rm":
 			// Check for help flag
 			if len(subArgs) > 0 && (subArgs[0] == "help" || subArgs[0] == "-h" || subArgs[0] == "--help") {
@@ -76,7 +113,7 @@ func Run(args []string) error {
 		}
 		return commands.RunUpgrade(commandArgs)
 	case "-v", "--version":
-		fmt.Printf("lancher %s\n", version.Get())
+		shared.Printf("lancher %s\n", version.Get())
 		return nil
 	case "help", "-h", "--help":
 		return runHelp()
@@ -86,23 +123,77 @@ func Run(args []string) error {
 	}
 }
 
+// parseGlobalFlags applies global flags and returns the remaining arguments
+// Global flags are accepted anywhere on the command line
+func parseGlobalFlags(args []string) ([]string, error) {
+	remaining := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--templates-dir":
+			if i+1 >= len(args) {
+				return nil, shared.FormatError("flag --templates-dir requires a value")
+			}
+			if err := storage.SetTemplatesDir(args[i+1]); err != nil {
+				return nil, shared.FormatError(fmt.Sprintf("invalid templates directory: %v", err))
+			}
+			i++
+		case strings.HasPrefix(arg, "--templates-dir="):
+			if err := storage.SetTemplatesDir(strings.TrimPrefix(arg, "--templates-dir=")); err != nil {
+				return nil, shared.FormatError(fmt.Sprintf("invalid templates directory: %v", err))
+			}
+		case arg == "--output":
+			if i+1 >= len(args) {
+				return nil, shared.FormatError("flag --output requires a value")
+			}
+			if err := shared.SetOutputFormat(args[i+1]); err != nil {
+				return nil, shared.FormatErrorCode(shared.ErrCodeInvalidArgument, err.Error())
+			}
+			i++
+		case strings.HasPrefix(arg, "--output="):
+			if err := shared.SetOutputFormat(strings.TrimPrefix(arg, "--output=")); err != nil {
+				return nil, shared.FormatErrorCode(shared.ErrCodeInvalidArgument, err.Error())
+			}
+		case arg == "--json":
+			shared.SetOutputFormat(shared.OutputJSON)
+		default:
+			remaining = append(remaining, arg)
+		}
+	}
+
+	return remaining, nil
+}
+
 // runHelp displays usage information
 func runHelp() error {
-	fmt.Printf("%slancher%s %s%s%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset, shared.ColorBold, version.Get(), shared.ColorReset)
-	fmt.Printf("A minimal local project template manager\n\n")
+	shared.Printf("%slancher%s %s%s%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset, shared.ColorBold, version.Get(), shared.ColorReset)
+	shared.Printf("A minimal local project template manager\n\n")
+
+	shared.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    lancher <command> [args...] [options]\n\n")
+
+	shared.Printf("%sCOMMANDS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s%-20s%s %s\n", shared.ColorGreen, "create", shared.ColorReset, "Create a new project from template")
+	shared.Printf("    %s%-20s%s %s\n", shared.ColorGreen, "template", shared.ColorReset, "Manage templates (add, list, update, remove)")
+	shared.Printf("    %s%-20s%s %s\n", shared.ColorGreen, "templates", shared.ColorReset, "List all available templates")
+	shared.Printf("    %s%-20s%s %s\n", shared.ColorGreen, "upgrade", shared.ColorReset, "Check for updates and upgrade to latest version")
+	shared.Printf("    %shelp%s, %s-h%s             %s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "Print this help message")
 
-	fmt.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    lancher <command> [args...] [options]\n\n")
+	shared.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s--templates-dir%s %s<path>%s  %s\n", shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "Use a different templates directory")
+	shared.Printf("    %s--output%s %s<format>%s       %s\n", shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "Output format: text or json")
+	shared.Printf("    %s--json%s                  %s\n", shared.ColorGreen, shared.ColorReset, "Same as --output json")
+	shared.Printf("    %s-v%s, %s--version%s        %s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "Print version information")
+	shared.Printf("    %s-h%s, %s--help%s           %s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "Show help for any command")
 
-	fmt.Printf("%sCOMMANDS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    %s%-20s%s %s\n", shared.ColorGreen, "create", shared.ColorReset, "Create a new project from template")
-	fmt.Printf("    %s%-20s%s %s\n", shared.ColorGreen, "template", shared.ColorReset, "Manage templates (add, list, update, remove)")
-	fmt.Printf("    %s%-20s%s %s\n", shared.ColorGreen, "templates", shared.ColorReset, "List all available templates")
-	fmt.Printf("    %s%-20s%s %s\n", shared.ColorGreen, "upgrade", shared.ColorReset, "Check for updates and upgrade to latest version")
-	fmt.Printf("    %shelp%s, %s-h%s             %s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "Print this help message")
+	shared.Printf("%sENVIRONMENT:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s%-22s%s %s\n", shared.ColorGreen, storage.EnvTemplatesDir, shared.ColorReset, "Templates directory (overridden by --templates-dir)")
+	shared.Printf("    %s%-22s%s %s\n\n", shared.ColorGreen, storage.EnvHome, shared.ColorReset, "lancher home; templates are stored in $LANCHER_HOME/templates")
 
-	fmt.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    %s-v%s, %s--version%s        %s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "Print version information")
-	fmt.Printf("    %s-h%s, %s--help%s           %s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "Show help for any command")
+	shared.Printf("%sJSON OUTPUT:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    template list, template info, create and upgrade print their result to stdout\n")
+	shared.Printf("    as JSON; progress goes to stderr. Errors are printed to stdout as\n")
+	shared.Printf("    {\"error\": {\"code\": ..., \"message\": ...}} and exit with status 1.\n")
 	return nil
 }
@@ -5,39 +5,66 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/lancher-dev/lancher/internal/cli/shared"
 	"github.com/lancher-dev/lancher/internal/config"
 	"github.com/lancher-dev/lancher/internal/fileutil"
+	"github.com/lancher-dev/lancher/internal/gitutil"
+	"github.com/lancher-dev/lancher/internal/sources"
 	"github.com/lancher-dev/lancher/internal/storage"
+	"github.com/lancher-dev/lancher/internal/versions"
 )
 
 // RunCreateHelp displays help for create command
 func RunCreateHelp() error {
-	fmt.Printf("%slancher create%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("Create a new project from template\n\n")
-
-	fmt.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    lancher create [options]\n\n")
-
-	fmt.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    %s-t%s, %s--template%s %s<name>%s     %sTemplate name to use%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "", "")
-	fmt.Printf("    %s-d%s, %s--destination%s %s<path>%s  %sDestination directory for the project%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "", "")
-	fmt.Printf("    %s    --git%s                 %sInitialize git repository automatically%s\n", shared.ColorGreen, shared.ColorReset, "", "")
-	fmt.Printf("    %s    --no-git%s              %sSkip git initialization prompt%s\n", shared.ColorGreen, shared.ColorReset, "", "")
-	fmt.Printf("    %s    --hooks%s               %sExecute hooks automatically (skip prompt)%s\n", shared.ColorGreen, shared.ColorReset, "", "")
-	fmt.Printf("    %s    --no-hooks%s            %sSkip hooks execution%s\n", shared.ColorGreen, shared.ColorReset, "", "")
-	fmt.Printf("    %s-p%s, %s--print%s               %sShow detailed output (no spinner)%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
-	fmt.Printf("    %s-h%s, %s--help%s                %sShow this help message%s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("%slancher create%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
+	shared.Printf("Create a new project from template\n\n")
+
+	shared.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    lancher create [options]\n\n")
+
+	shared.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s-t%s, %s--template%s %s<name>%s     %sTemplate name to use (name@version for a stored version)%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "", "")
+	shared.Printf("    %s-d%s, %s--destination%s %s<path>%s  %sDestination directory for the project%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "", "")
+	shared.Printf("    %s    --tag%s %s<tag>%s           %sOnly offer or accept templates with this tag (repeatable)%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "", "")
+	shared.Printf("    %s    --category%s %s<name>%s     %sOnly offer or accept templates in this category%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "", "")
+	shared.Printf("    %s    --namespace%s %s<name>%s    %sOnly offer or accept templates in this namespace%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "", "")
+	shared.Printf("    %s    --git%s                 %sInitialize git repository automatically%s\n", shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --no-git%s              %sSkip git initialization prompt%s\n", shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --hooks%s               %sExecute hooks automatically (skip prompt)%s\n", shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --no-hooks%s            %sSkip hooks execution%s\n", shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --symlinks%s %s<policy>%s   %sCopy symlinks: preserve (default), follow or skip%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "", "")
+	shared.Printf("    %s    --strip%s               %sExtract an archive source without its single top-level directory%s\n", shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s-p%s, %s--print%s               %sShow detailed output (no spinner)%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s-h%s, %s--help%s                %sShow this help message%s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+
+	shared.Printf("%sSOURCES:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    -t also accepts a source without adding it as a template:\n")
+	shared.Printf("    %s./dir%s, %s/abs/path%s, %s~/dir%s    Local directory or archive (must start with ./, ../, / or ~)\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset)
+	shared.Printf("    %sgh:%s<repo>%s[@ref]%s, %sgl:%s<repo>     GitHub or GitLab repository, optionally at a ref\n", shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, shared.ColorGreen, shared.ColorReset)
+	shared.Printf("    %s<git-url>%s%s[@ref]%s             Git repository\n", shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset)
+	shared.Printf("    %s<archive-url>%s               Archive over HTTP(S) (zip, tar, tar.gz, tar.xz, tar.zst)\n", shared.ColorGreen, shared.ColorReset)
+	shared.Printf("    Append %s//<path>%s to a repository to use one of its subdirectories\n", shared.ColorCyan, shared.ColorReset)
+	shared.Printf("    Git sources are cached in %s$XDG_CACHE_HOME/lancher/sources%s\n\n", shared.ColorCyan, shared.ColorReset)
+
+	shared.Printf("%sCONFIGURATION:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    Defaults for destination, git and hooks are read from the %screate%s section\n", shared.ColorGreen, shared.ColorReset)
+	shared.Printf("    of %s$XDG_CONFIG_HOME/lancher/config.yaml%s. Flags take precedence.\n\n", shared.ColorCyan, shared.ColorReset)
+
+	shared.Printf("%sJSON OUTPUT:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    With %s--output json%s nothing is prompted: -t and -d are required, the\n", shared.ColorGreen, shared.ColorReset)
+	shared.Printf("    destination must be empty and hooks and git run only when enabled.\n\n")
 
 	return nil
 }
 
 // runCreate creates a new project from a template
 func Run(args []string) error {
-	var templateName, destination string
-	var verbose, gitInit, noGit, executeHooks, noHooks bool
+	var templateName, destination, category, namespace, symlinks string
+	var tags []string
+	var verbose, gitInit, noGit, executeHooks, noHooks, strip bool
 
 	// Parse flags
 	for i := 0; i < len(args); i++ {
@@ -56,6 +83,34 @@ func Run(args []string) error {
 			} else {
 				return shared.FormatError("flag -d/--destination requires a value")
 			}
+		case "--tag":
+			if i+1 < len(args) {
+				tags = append(tags, args[i+1])
+				i++
+			} else {
+				return shared.FormatError("flag --tag requires a value")
+			}
+		case "--category":
+			if i+1 < len(args) {
+				category = args[i+1]
+				i++
+			} else {
+				return shared.FormatError("flag --category requires a value")
+			}
+		case "--namespace":
+			if i+1 < len(args) {
+				namespace = args[i+1]
+				i++
+			} else {
+				return shared.FormatError("flag --namespace requires a value")
+			}
+		case "--symlinks":
+			if i+1 < len(args) {
+				symlinks = args[i+1]
+				i++
+			} else {
+				return shared.FormatError("flag --symlinks requires a value")
+			}
 		case "--git":
 			gitInit = true
 		case "--no-git":
@@ -64,6 +119,8 @@ func Run(args []string) error {
 			executeHooks = true
 		case "--no-hooks":
 			noHooks = true
+		case "--strip":
+			strip = true
 		case "-p", "--print":
 			verbose = true
 		default:
@@ -75,6 +132,11 @@ func Run(args []string) error {
 		}
 	}
 
+	links, err := fileutil.ParseSymlinkPolicy(symlinks)
+	if err != nil {
+		return shared.FormatError(err.Error())
+	}
+
 	// Validate mutually exclusive flags
 	if gitInit && noGit {
 		return shared.FormatError("cannot use both --git and --no-git flags")
@@ -83,6 +145,34 @@ func Run(args []string) error {
 		return shared.FormatError("cannot use both --hooks and --no-hooks flags")
 	}
 
+	// Apply user config defaults for flags not provided
+	userCfg, err := config.LoadUserConfig()
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to load user config: %v", err))
+	}
+	if !gitInit && !noGit && userCfg.Create.Git != nil {
+		gitInit = *userCfg.Create.Git
+		noGit = !*userCfg.Create.Git
+	}
+	if !executeHooks && !noHooks {
+		switch userCfg.Create.Hooks {
+		case config.HooksAlways:
+			executeHooks = true
+		case config.HooksNever:
+			noHooks = true
+		}
+	}
+
+	// JSON output is for scripts, which cannot answer prompts
+	if shared.JSONOutput() {
+		if templateName == "" {
+			return shared.FormatErrorCode(shared.ErrCodeMissingArgument, "flag -t/--template is required with JSON output")
+		}
+		if destination == "" {
+			return shared.FormatErrorCode(shared.ErrCodeMissingArgument, "flag -d/--destination is required with JSON output")
+		}
+	}
+
 	// Interactive mode if flags not provided
 	if templateName == "" {
 		// List available templates
@@ -92,16 +182,26 @@ func Run(args []string) error {
 		}
 
 		if len(templates) == 0 {
-			fmt.Printf("%sNo templates found.%s\n", shared.ColorYellow, shared.ColorReset)
-			fmt.Printf("Add a template with: %slancher template add <name> <source_dir>%s\n", shared.ColorCyan, shared.ColorReset)
+			shared.Printf("%sNo templates found.%s\n", shared.ColorYellow, shared.ColorReset)
+			shared.Printf("Add a template with: %slancher template add <name> <source_dir>%s\n", shared.ColorCyan, shared.ColorReset)
+			return nil
+		}
+
+		options, err := templateOptions(templates, tags, category, namespace)
+		if err != nil {
+			return shared.FormatError(fmt.Sprintf("failed to load templates: %v", err))
+		}
+
+		if len(options) == 0 {
+			shared.Printf("%sNo templates match the given filters.%s\n", shared.ColorYellow, shared.ColorReset)
 			return nil
 		}
 
 		// Use interactive select
-		selectedTemplate, err := shared.Select("Choose a template:", templates)
+		selectedTemplate, err := shared.SelectWithOptions("Choose a template:", options)
 		if err != nil {
 			if strings.Contains(err.Error(), "cancelled") {
-				fmt.Printf("%sCancelled.%s\n", shared.ColorYellow, shared.ColorReset)
+				shared.Printf("%sCancelled.%s\n", shared.ColorYellow, shared.ColorReset)
 				return nil
 			}
 			return shared.FormatError(fmt.Sprintf("selection failed: %v", err))
@@ -109,34 +209,81 @@ func Run(args []string) error {
 		templateName = selectedTemplate
 
 		// Show selected template
-		fmt.Printf("%s✓ Template selected:%s %s\n", shared.ColorGreen, shared.ColorReset, templateName)
+		shared.Printf("%s✓ Template selected:%s %s\n", shared.ColorGreen, shared.ColorReset, templateName)
 	}
 
 	if destination == "" {
-		dest, err := shared.PromptStringWithDefault("Enter destination directory:", "my-app")
+		defaultDest := "my-app"
+		if userCfg.Create.Destination != "" {
+			defaultDest = userCfg.Create.Destination
+		}
+		dest, err := shared.PromptStringWithDefault("Enter destination directory:", defaultDest)
 		if err != nil {
 			if strings.Contains(err.Error(), "cancelled") {
-				fmt.Printf("%sCancelled.%s\n", shared.ColorYellow, shared.ColorReset)
+				shared.Printf("%sCancelled.%s\n", shared.ColorYellow, shared.ColorReset)
 				return nil
 			}
 			return shared.FormatError("failed to read input")
 		}
 		destination = dest
-		fmt.Printf("%s✓ Destination set:%s %s\n", shared.ColorGreen, shared.ColorReset, destination)
+		shared.Printf("%s✓ Destination set:%s %s\n", shared.ColorGreen, shared.ColorReset, destination)
 	}
 
-	// Validate template name
-	if err := shared.SanitizeTemplateName(templateName); err != nil {
-		return shared.FormatError(err.Error())
-	}
+	// Filters also apply to a template given with -t, so a mismatch is not silently ignored
+	filtered := len(tags) > 0 || category != "" || namespace != ""
 
-	// Check if template exists
-	exists, err := storage.TemplateExists(templateName)
-	if err != nil {
-		return shared.FormatError(fmt.Sprintf("failed to check template: %v", err))
-	}
-	if !exists {
-		return shared.FormatError(fmt.Sprintf("template '%s' not found", templateName))
+	var templatePath string
+	if source := userCfg.ExpandAlias(templateName); sources.IsSource(source) {
+		if filtered {
+			return shared.FormatErrorCode(shared.ErrCodeInvalidArgument, "--tag, --category and --namespace select registered templates and cannot be used with a path or URL")
+		}
+		// Paths, git URLs and aliases are used directly without registering a template
+		path, cleanup, err := resolveSource(source, strip, verbose)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		templatePath = path
+	} else {
+		if strip {
+			return shared.FormatErrorCode(shared.ErrCodeInvalidArgument, "--strip can only be used with an archive path or URL")
+		}
+		// A specific version can be requested with name@version
+		var version string
+		templateName, version = splitTemplateVersion(templateName)
+
+		// Validate template name
+		if err := shared.SanitizeTemplateName(templateName); err != nil {
+			return shared.FormatError(err.Error())
+		}
+
+		// Resolve template across project, user and system roots
+		tmpl, found, err := storage.FindTemplate(templateName)
+		if err != nil {
+			return shared.FormatError(fmt.Sprintf("failed to check template: %v", err))
+		}
+		if !found {
+			return shared.FormatNotFoundError(templateName)
+		}
+		if target, linked := storage.LinkTarget(tmpl.Path); linked {
+			if _, err := os.Stat(tmpl.Path); err != nil {
+				return shared.FormatError(fmt.Sprintf("template '%s' is linked to %s, which no longer exists", templateName, target))
+			}
+		}
+
+		if filtered {
+			if err := checkTemplateFilter(tmpl, tags, category, namespace); err != nil {
+				return err
+			}
+		}
+
+		templatePath = tmpl.Path
+		if version != "" {
+			templatePath, err = resolveTemplateVersion(tmpl, version, verbose)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	// Handle destination path resolution
@@ -177,10 +324,13 @@ func Run(args []string) error {
 			return shared.FormatError(fmt.Sprintf("failed to read destination directory: %v", err))
 		}
 
+		if len(entries) > 0 && shared.JSONOutput() {
+			return shared.FormatErrorCode(shared.ErrCodeDestinationExists, fmt.Sprintf("destination directory is not empty: %s", destAbs))
+		}
 		if len(entries) > 0 {
 			// Directory is not empty - ask for confirmation
-			fmt.Printf("%s⚠ Warning:%s Destination directory is not empty (%d items)\n", shared.ColorYellow, shared.ColorReset, len(entries))
-			fmt.Printf("  %sLocation:%s %s\n", shared.ColorYellow, shared.ColorReset, destAbs)
+			shared.Printf("%s⚠ Warning:%s Destination directory is not empty (%d items)\n", shared.ColorYellow, shared.ColorReset, len(entries))
+			shared.Printf("  %sLocation:%s %s\n", shared.ColorYellow, shared.ColorReset, destAbs)
 
 			confirmed, err := shared.PromptConfirmWithDefault("Do you want to remove and recreate the directory?", false)
 			if err != nil {
@@ -188,7 +338,7 @@ func Run(args []string) error {
 			}
 
 			if !confirmed {
-				fmt.Printf("%sCancelled.%s\n", shared.ColorYellow, shared.ColorReset)
+				shared.Printf("%sCancelled.%s\n", shared.ColorYellow, shared.ColorReset)
 				return nil
 			}
 
@@ -196,16 +346,10 @@ func Run(args []string) error {
 			if err := os.RemoveAll(destAbs); err != nil {
 				return shared.FormatError(fmt.Sprintf("failed to remove existing directory: %v", err))
 			}
-			fmt.Printf("%s✓ Removed existing directory%s\n", shared.ColorGreen, shared.ColorReset)
+			shared.Printf("%s✓ Removed existing directory%s\n", shared.ColorGreen, shared.ColorReset)
 		}
 	}
 
-	// Get template path
-	templatePath, err := storage.GetTemplatePath(templateName)
-	if err != nil {
-		return shared.FormatError(fmt.Sprintf("failed to get template path: %v", err))
-	}
-
 	// Load template configuration if exists
 	cfg, err := config.LoadConfig(templatePath)
 	if err != nil {
@@ -216,9 +360,9 @@ func Run(args []string) error {
 	if cfg != nil {
 		metadata := cfg.GetMetadata()
 		if metadata != "" {
-			fmt.Printf("\n%sTemplate Information:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-			fmt.Print(metadata)
-			fmt.Println()
+			shared.Printf("\n%sTemplate Information:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+			shared.Print(metadata)
+			shared.Println()
 		}
 	}
 
@@ -229,10 +373,10 @@ func Run(args []string) error {
 		spinner.Start()
 		defer spinner.Stop()
 	} else {
-		fmt.Printf("%sCreating project...%s\n", shared.ColorYellow, shared.ColorReset)
+		shared.Printf("%sCreating project...%s\n", shared.ColorYellow, shared.ColorReset)
 	}
 
-	if err := copyTemplate(templatePath, destAbs, cfg); err != nil {
+	if err := copyTemplate(templatePath, destAbs, cfg, links); err != nil {
 		if spinner != nil {
 			spinner.Fail(fmt.Sprintf("Failed to create project: %v", err))
 		}
@@ -242,26 +386,36 @@ func Run(args []string) error {
 	if spinner != nil {
 		spinner.Success(fmt.Sprintf("Project created successfully from template '%s'", templateName))
 	} else {
-		fmt.Printf("%s✓ Project created successfully from template '%s'%s\n", shared.ColorGreen, templateName, shared.ColorReset)
+		shared.Printf("%s✓ Project created successfully from template '%s'%s\n", shared.ColorGreen, templateName, shared.ColorReset)
+	}
+	shared.Printf("  %sLocation:%s %s\n", shared.ColorYellow, shared.ColorReset, destAbs)
+
+	result := createResult{
+		Template:    templateName,
+		Source:      templatePath,
+		Destination: destAbs,
+		Hooks:       []string{},
 	}
-	fmt.Printf("  %sLocation:%s %s\n", shared.ColorYellow, shared.ColorReset, destAbs)
 
 	// Execute hooks if defined
+	if cfg.HasHooks() {
+		result.Hooks = cfg.Hooks
+	}
 	if cfg != nil && cfg.HasHooks() && !noHooks {
-		fmt.Printf("\n%sHooks found:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+		shared.Printf("\n%sHooks found:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
 		for i, hook := range cfg.Hooks {
-			fmt.Printf("  %d. %s\n", i+1, hook)
+			shared.Printf("  %d. %s\n", i+1, hook)
 		}
-		fmt.Println()
+		shared.Println()
 
 		// Check for lancher commands in hooks (prevent infinite loops)
 		if hasLancherCommands(cfg.Hooks) {
-			fmt.Printf("%s⚠ Warning: Hooks contain lancher commands - skipping execution to prevent infinite loops%s\n", shared.ColorYellow, shared.ColorReset)
-			fmt.Printf("%sPlease remove lancher commands from hooks and run them manually if needed%s\n", shared.ColorYellow, shared.ColorReset)
+			shared.Printf("%s⚠ Warning: Hooks contain lancher commands - skipping execution to prevent infinite loops%s\n", shared.ColorYellow, shared.ColorReset)
+			shared.Printf("%sPlease remove lancher commands from hooks and run them manually if needed%s\n", shared.ColorYellow, shared.ColorReset)
 		} else {
 			var confirmed bool
-			if executeHooks {
-				confirmed = true
+			if executeHooks || shared.JSONOutput() {
+				confirmed = executeHooks
 			} else {
 				var err error
 				confirmed, err = shared.PromptConfirmWithDefault("Execute hooks?", true)
@@ -271,21 +425,23 @@ func Run(args []string) error {
 			}
 
 			if confirmed {
-				if err := runHooks(cfg.Hooks, destAbs); err != nil {
-					fmt.Printf("%s⚠ Some hooks failed: %v%s\n", shared.ColorYellow, err, shared.ColorReset)
+				result.HooksRun = true
+				if err := runHooks(cfg.Hooks, destAbs, userCfg.HookEnv()); err != nil {
+					result.HooksError = err.Error()
+					shared.Printf("%s⚠ Some hooks failed: %v%s\n", shared.ColorYellow, err, shared.ColorReset)
 				} else {
-					fmt.Printf("%s✓ All hooks executed successfully%s\n", shared.ColorGreen, shared.ColorReset)
+					shared.Printf("%s✓ All hooks executed successfully%s\n", shared.ColorGreen, shared.ColorReset)
 				}
 			} else {
-				fmt.Printf("%sSkipped hooks%s\n", shared.ColorYellow, shared.ColorReset)
+				shared.Printf("%sSkipped hooks%s\n", shared.ColorYellow, shared.ColorReset)
 			}
 		}
 	}
 
 	// Ask to initialize git repository (if not set via flag)
-	fmt.Println()
+	shared.Println()
 	if !noGit {
-		if !gitInit {
+		if !gitInit && !shared.JSONOutput() {
 			var err error
 			gitInit, err = shared.PromptConfirmWithDefault("Initialize git repository?", false)
 			if err != nil {
@@ -297,67 +453,317 @@ func Run(args []string) error {
 			cmd := exec.Command("git", "init")
 			cmd.Dir = destAbs
 			if output, err := cmd.CombinedOutput(); err != nil {
-				fmt.Printf("%s⚠ Failed to initialize git: %v%s\n", shared.ColorYellow, err, shared.ColorReset)
+				result.GitError = err.Error()
+				shared.Printf("%s⚠ Failed to initialize git: %v%s\n", shared.ColorYellow, err, shared.ColorReset)
 				if len(output) > 0 {
-					fmt.Printf("%s%s%s\n", shared.ColorGray, string(output), shared.ColorReset)
+					shared.Printf("%s%s%s\n", shared.ColorGray, string(output), shared.ColorReset)
 				}
 			} else {
-				fmt.Printf("%s✓ Git repository initialized%s\n", shared.ColorGreen, shared.ColorReset)
+				result.GitInitialized = true
+				shared.Printf("%s✓ Git repository initialized%s\n", shared.ColorGreen, shared.ColorReset)
 			}
 		} else {
-			fmt.Printf("%sSkipped git initialization%s\n", shared.ColorYellow, shared.ColorReset)
+			shared.Printf("%sSkipped git initialization%s\n", shared.ColorYellow, shared.ColorReset)
 		}
 	}
 
+	if shared.JSONOutput() {
+		return shared.PrintJSON(result)
+	}
+	return nil
+}
+
+// createResult describes a created project in JSON output
+type createResult struct {
+	Template       string   `json:"template"`
+	Source         string   `json:"source"` // Directory the project was copied from
+	Destination    string   `json:"destination"`
+	Hooks          []string `json:"hooks"`
+	HooksRun       bool     `json:"hooks_run"`
+	HooksError     string   `json:"hooks_error,omitempty"`
+	GitInitialized bool     `json:"git_initialized"`
+	GitError       string   `json:"git_error,omitempty"`
+}
+
+// checkTemplateFilter returns an error if a template does not match the --tag, --category
+// and --namespace filters
+func checkTemplateFilter(tmpl storage.Template, tags []string, category, namespace string) error {
+	if ns, _ := storage.SplitName(tmpl.Name); namespace != "" && ns != namespace {
+		return shared.FormatErrorCode(shared.ErrCodeInvalidArgument, fmt.Sprintf("template '%s' is not in namespace '%s'", tmpl.Name, namespace))
+	}
+
+	cfg, err := config.LoadConfig(tmpl.Path)
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to load template config: %v", err))
+	}
+	if !cfg.MatchesFilter(tags, category) {
+		var filters []string
+		for _, tag := range tags {
+			filters = append(filters, fmt.Sprintf("tag '%s'", tag))
+		}
+		if category != "" {
+			filters = append(filters, fmt.Sprintf("category '%s'", category))
+		}
+		return shared.FormatErrorCode(shared.ErrCodeInvalidArgument, fmt.Sprintf("template '%s' does not match %s", tmpl.Name, strings.Join(filters, " and ")))
+	}
 	return nil
 }
 
-// copyTemplate copies template directory respecting ignore patterns
-func copyTemplate(srcPath, dstPath string, cfg *config.Config) error {
-	return filepath.Walk(srcPath, func(path string, info os.FileInfo, err error) error {
+// templateOptions builds selection options for the templates matching the filters,
+// grouped by category (uncategorized templates last)
+func templateOptions(templates []string, tags []string, category, namespace string) ([]shared.SelectOption, error) {
+	var options []shared.SelectOption
+	for _, name := range templates {
+		if ns, _ := storage.SplitName(name); namespace != "" && ns != namespace {
+			continue
+		}
+
+		tmpl, found, err := storage.FindTemplate(name)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		// Linked templates whose directory is gone cannot be created from
+		if _, err := os.Stat(tmpl.Path); err != nil {
+			continue
 		}
 
-		// Calculate relative path
-		relPath, err := filepath.Rel(srcPath, path)
+		cfg, err := config.LoadConfig(tmpl.Path)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if !cfg.MatchesFilter(tags, category) {
+			continue
 		}
 
-		// Skip any lancher config files
-		fileName := filepath.Base(relPath)
-		for _, configFile := range config.ConfigFileNames {
-			if fileName == configFile {
-				return nil
-			}
+		options = append(options, shared.SelectOption{
+			Value:       name,
+			Label:       name,
+			Group:       cfg.GetCategory(),
+			Description: cfg.GetDescription(),
+		})
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		gi, gj := options[i].Group, options[j].Group
+		if (gi == "") != (gj == "") {
+			return gj == ""
+		}
+		return strings.ToLower(gi) < strings.ToLower(gj)
+	})
+
+	return options, nil
+}
+
+// resolveSource returns a directory holding the files of a source given instead of a template name
+// Remote sources are fetched into the cache; archives are extracted into a temporary
+// directory that is removed by the returned cleanup function
+// strip removes the single top-level directory of an archive and is rejected for other sources
+func resolveSource(source string, strip, verbose bool) (string, func(), error) {
+	noop := func() {}
+	stripErr := shared.FormatErrorCode(shared.ErrCodeInvalidArgument, "--strip can only be used with an archive path or URL")
+
+	if sources.IsPath(source) {
+		path, err := filepath.Abs(config.ExpandPath(source, ""))
+		if err != nil {
+			return "", noop, shared.FormatError(fmt.Sprintf("invalid source path: %v", err))
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", noop, shared.FormatError(fmt.Sprintf("source not found: %s", path))
 		}
 
-		// Skip .git directory (always excluded from templates)
-		if relPath == ".git" || strings.HasPrefix(relPath, ".git"+string(filepath.Separator)) {
-			if info.IsDir() {
-				return filepath.SkipDir
+		if info.IsDir() {
+			if strip {
+				return "", noop, stripErr
 			}
-			return nil
+			return path, noop, nil
+		}
+		if !sources.IsArchive(path) {
+			return "", noop, shared.FormatError(fmt.Sprintf("source is not a directory or archive: %s", path))
 		}
 
-		// Check ignore patterns
-		if cfg != nil && cfg.ShouldIgnore(relPath) {
-			if info.IsDir() {
-				return filepath.SkipDir
+		tmp, err := os.MkdirTemp("", "lancher-source-*")
+		if err != nil {
+			return "", noop, shared.FormatError(fmt.Sprintf("failed to create temporary directory: %v", err))
+		}
+		cleanup := func() { os.RemoveAll(tmp) }
+		if err := fileutil.ExtractArchive(path, tmp, fileutil.ExtractOptions{StripTopLevel: strip}); err != nil {
+			cleanup()
+			return "", noop, shared.FormatError(fmt.Sprintf("failed to extract archive: %v", err))
+		}
+		return tmp, cleanup, nil
+	}
+
+	// Links without an archive extension are archives when given --strip; other ones are
+	// fetched with git first and only probed as archives if that fails
+	if sources.DetectArchiveURL(source, "") || (strip && sources.IsHTTPURL(source)) {
+		return resolveArchiveURL(source, strip, verbose)
+	}
+	if strip {
+		return "", noop, stripErr
+	}
+
+	// Both "repo//subdir@ref" and "repo@ref//subdir" are accepted
+	source, ref, subdir := sources.SplitRemote(source)
+	if subdir != "" {
+		cleaned, err := sources.CleanSubdir(subdir)
+		if err != nil {
+			return "", noop, shared.FormatError(err.Error())
+		}
+		subdir = cleaned
+	}
+
+	var spinner *shared.Spinner
+	writer := shared.NewSpinnerWriter(verbose)
+	if !verbose {
+		spinner = shared.NewSpinner("Fetching source...")
+		spinner.Start()
+		defer spinner.Stop()
+	} else {
+		shared.Printf("%sFetching source...%s\n", shared.ColorYellow, shared.ColorReset)
+	}
+
+	dir, err := sources.Fetch(source, ref, writer.MultiWriter())
+	if err != nil {
+		// A link that is not a repository may still serve an archive
+		if ref == "" && subdir == "" && sources.ProbeArchiveURL(source) {
+			if spinner != nil {
+				spinner.Stop()
 			}
-			return nil
+			return resolveArchiveURL(source, false, verbose)
+		}
+		if spinner != nil {
+			spinner.Fail(fmt.Sprintf("Failed to fetch source: %v", err))
 		}
+		return "", noop, shared.FormatError(fmt.Sprintf("failed to fetch %s: %v", source, err))
+	}
 
-		// Construct destination path
-		targetPath := filepath.Join(dstPath, relPath)
+	if spinner != nil {
+		spinner.Success(fmt.Sprintf("Fetched %s", source))
+	} else {
+		shared.Printf("%s✓ Fetched %s%s\n", shared.ColorGreen, source, shared.ColorReset)
+	}
 
-		if info.IsDir() {
-			return os.MkdirAll(targetPath, info.Mode())
+	if subdir != "" {
+		dir = filepath.Join(dir, filepath.FromSlash(subdir))
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return "", noop, shared.FormatError(fmt.Sprintf("subdirectory '%s' not found in %s", subdir, source))
 		}
+	}
+	return dir, noop, nil
+}
 
-		return fileutil.CopyFile(path, targetPath)
-	})
+// resolveArchiveURL downloads and extracts an archive URL into a temporary directory
+// The returned cleanup function removes it
+func resolveArchiveURL(url string, strip, verbose bool) (string, func(), error) {
+	noop := func() {}
+
+	tmp, err := os.MkdirTemp("", "lancher-source-*")
+	if err != nil {
+		return "", noop, shared.FormatError(fmt.Sprintf("failed to create temporary directory: %v", err))
+	}
+	cleanup := func() { os.RemoveAll(tmp) }
+
+	var spinner *shared.Spinner
+	if !verbose {
+		spinner = shared.NewSpinner("Downloading archive...")
+		spinner.Start()
+		defer spinner.Stop()
+	} else {
+		shared.Printf("%sDownloading archive...%s\n", shared.ColorYellow, shared.ColorReset)
+	}
+
+	if _, err := sources.FetchArchive(url, tmp, "", fileutil.ExtractOptions{StripTopLevel: strip}); err != nil {
+		cleanup()
+		if spinner != nil {
+			spinner.Fail(fmt.Sprintf("Failed to download archive: %v", err))
+		}
+		return "", noop, shared.FormatError(fmt.Sprintf("failed to download %s: %v", url, err))
+	}
+
+	if spinner != nil {
+		spinner.Success(fmt.Sprintf("Downloaded %s", url))
+	} else {
+		shared.Printf("%s✓ Downloaded %s%s\n", shared.ColorGreen, url, shared.ColorReset)
+	}
+	return tmp, cleanup, nil
+}
+
+// splitTemplateVersion splits "name@version" into its parts
+func splitTemplateVersion(name string) (string, string) {
+	if i := strings.LastIndex(name, "@"); i > 0 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
+
+// resolveTemplateVersion returns the directory holding the requested version of a template
+// Stored snapshots and tags are checked first; git templates fetch missing tags from their remote
+// Versions of every root are kept in the user templates dir, the only one known to be writable
+func resolveTemplateVersion(tmpl storage.Template, version string, verbose bool) (string, error) {
+	if version == "" {
+		return "", shared.FormatError("missing version after '@'")
+	}
+
+	// The current copy already is the requested version
+	if cfg, _ := config.LoadConfig(tmpl.Path); cfg != nil && cfg.Version == version {
+		return tmpl.Path, nil
+	}
+
+	root, err := storage.GetTemplatesDir()
+	if err != nil {
+		return "", shared.FormatError(fmt.Sprintf("failed to get templates directory: %v", err))
+	}
+	stored, err := versions.Find(root, tmpl.QualifiedName(), version)
+	if err != nil {
+		return "", shared.FormatError(fmt.Sprintf("failed to read versions: %v", err))
+	}
+	if stored != nil {
+		shared.Printf("%s✓ Using %s %s of '%s'%s\n", shared.ColorGreen, stored.Kind, stored.ID, tmpl.Name, shared.ColorReset)
+		return stored.Path, nil
+	}
+
+	if !gitutil.IsRepository(tmpl.Path) {
+		return "", shared.FormatError(fmt.Sprintf("version '%s' of template '%s' not found\nRun 'lancher template versions %s' to list available versions", version, tmpl.Name, tmpl.Name))
+	}
+
+	remote, err := gitutil.RemoteURL(tmpl.Path)
+	if err != nil {
+		return "", shared.FormatError(fmt.Sprintf("failed to read git remote: %v", err))
+	}
+
+	var spinner *shared.Spinner
+	writer := shared.NewSpinnerWriter(verbose)
+	if !verbose {
+		spinner = shared.NewSpinner(fmt.Sprintf("Fetching version %s...", version))
+		spinner.Start()
+		defer spinner.Stop()
+	} else {
+		shared.Printf("%sFetching version %s...%s\n", shared.ColorYellow, version, shared.ColorReset)
+	}
+
+	fetched, err := versions.FetchTag(root, tmpl.QualifiedName(), remote, version, writer.MultiWriter())
+	if err != nil {
+		if spinner != nil {
+			spinner.Fail(fmt.Sprintf("Version '%s' not found", version))
+		}
+		return "", shared.FormatError(fmt.Sprintf("version '%s' of template '%s' not found: %v", version, tmpl.Name, err))
+	}
+
+	if spinner != nil {
+		spinner.Success(fmt.Sprintf("Using tag %s of '%s'", version, tmpl.Name))
+	} else {
+		shared.Printf("%s✓ Using tag %s of '%s'%s\n", shared.ColorGreen, version, tmpl.Name, shared.ColorReset)
+	}
+	return fetched.Path, nil
+}
+
+// copyTemplate copies template directory respecting ignore patterns and the symlink policy
+func copyTemplate(srcPath, dstPath string, cfg *config.Config, links fileutil.SymlinkPolicy) error {
+	return fileutil.CopyTreeWithLinks(srcPath, dstPath, cfg.IgnoreOptions(), links)
 }
 
 // hasLancherCommands checks if any hook contains lancher commands to prevent infinite loops
@@ -393,17 +799,19 @@ func hasLancherCommands(hooks []string) bool {
 }
 
 // runHooks runs hooks in the project directory
-func runHooks(hooks []string, projectDir string) error {
+// env is appended to the current environment of each hook
+func runHooks(hooks []string, projectDir string, env []string) error {
 	for i, hook := range hooks {
-		fmt.Printf("\n%sExecuting hook %d/%d:%s %s\n", shared.ColorCyan, i+1, len(hooks), shared.ColorReset, hook)
+		shared.Printf("\n%sExecuting hook %d/%d:%s %s\n", shared.ColorCyan, i+1, len(hooks), shared.ColorReset, hook)
 
 		// Execute hook via shell to properly handle quotes and redirects
 		var cmd *exec.Cmd
 		cmd = exec.Command("sh", "-c", hook)
 
 		cmd.Dir = projectDir
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(), env...)
+		cmd.Stdout = shared.Stdout()
+		cmd.Stderr = shared.Stderr()
 
 		if err := cmd.Run(); err != nil {
 			return fmt.Errorf("hook '%s' failed: %w", hook, err)
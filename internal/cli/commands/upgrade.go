@@ -20,6 +20,15 @@ const (
 	installScriptURL = "https://raw.githubusercontent.com/lancher-dev/lancher/main/bin/install.sh"
 )
 
+// upgradeResult describes the outcome of an upgrade in JSON output
+type upgradeResult struct {
+	CurrentVersion  string `json:"current_version"`
+	LatestVersion   string `json:"latest_version"`
+	UpdateAvailable bool   `json:"update_available"`
+	Upgraded        bool   `json:"upgraded"`
+	ReleaseURL      string `json:"release_url,omitempty"`
+}
+
 type GitHubRelease struct {
 	TagName string `json:"tag_name"`
 	Name    string `json:"name"`
@@ -28,28 +37,34 @@ type GitHubRelease struct {
 
 // RunUpgradeHelp displays help for upgrade command
 func RunUpgradeHelp() error {
-	fmt.Printf("%slancher upgrade%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("Check for updates and upgrade to the latest version\n\n")
+	shared.Printf("%slancher upgrade%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
+	shared.Printf("Check for updates and upgrade to the latest version\n\n")
 
-	fmt.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    lancher upgrade [options]\n\n")
+	shared.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    lancher upgrade [options]\n\n")
 
-	fmt.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    %s-f%s, %s--force%s   %sForce upgrade even if already on latest version%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
-	fmt.Printf("    %s-h%s, %s--help%s    %sShow this help message%s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s-f%s, %s--force%s   %sForce upgrade even if already on latest version%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s-y%s, %s--yes%s     %sUpgrade without asking for confirmation%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s-h%s, %s--help%s    %sShow this help message%s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+
+	shared.Printf("%sJSON OUTPUT:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    With %s--output json%s only the check is reported unless %s--yes%s is given.\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset)
 
 	return nil
 }
 
 // RunUpgrade checks for updates and upgrades lancher
 func RunUpgrade(args []string) error {
-	var force bool
+	var force, yes bool
 
 	// Parse flags
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
 		case "-f", "--force":
 			force = true
+		case "-y", "--yes":
+			yes = true
 		default:
 			if strings.HasPrefix(args[i], "-") {
 				usage := "USAGE:\n    lancher upgrade [OPTIONS]"
@@ -68,7 +83,7 @@ func RunUpgrade(args []string) error {
 		currentVersion = "development build"
 	}
 
-	fmt.Printf("%sCurrent version:%s %s\n", shared.ColorCyan, shared.ColorReset, currentVersion)
+	shared.Printf("%sCurrent version:%s %s\n", shared.ColorCyan, shared.ColorReset, currentVersion)
 
 	// Check for latest release
 	spinner := shared.NewSpinner("Checking for updates...")
@@ -85,23 +100,38 @@ func RunUpgrade(args []string) error {
 
 	spinner.Stop()
 
-	fmt.Printf("%sLatest version:%s %s\n", shared.ColorCyan, shared.ColorReset, latestVersion)
+	shared.Printf("%sLatest version:%s %s\n", shared.ColorCyan, shared.ColorReset, latestVersion)
 
-	// Compare versions
-	if !force && currentVersionClean == latestVersion {
-		fmt.Printf("%s✓ You are already on the latest version%s\n", shared.ColorGreen, shared.ColorReset)
-		return nil
+	// Compare versions; development builds count as newer than any release
+	comparison := version.Compare(currentVersionClean, latestVersion)
+	if currentVersion == "development build" {
+		comparison = 1
 	}
 
-	if !force && currentVersionClean > latestVersion {
-		fmt.Printf("%s✓ You are on a newer version than the latest release%s\n", shared.ColorGreen, shared.ColorReset)
-		return nil
+	result := upgradeResult{
+		CurrentVersion:  currentVersion,
+		LatestVersion:   latestVersion,
+		UpdateAvailable: comparison < 0,
+		ReleaseURL:      latestRelease.HTMLURL,
+	}
+
+	if !force && comparison == 0 {
+		shared.Printf("%s✓ You are already on the latest version%s\n", shared.ColorGreen, shared.ColorReset)
+		return printUpgradeResult(result)
+	}
+
+	if !force && comparison > 0 {
+		shared.Printf("%s✓ You are on a newer version than the latest release%s\n", shared.ColorGreen, shared.ColorReset)
+		return printUpgradeResult(result)
 	}
 
 	// Ask for confirmation
-	fmt.Println()
+	shared.Println()
 	var confirmed bool
-	if force {
+	if yes || shared.JSONOutput() {
+		// JSON output cannot answer a prompt, so only --yes upgrades
+		confirmed = yes
+	} else if force {
 		conf, err := shared.PromptConfirmWithDefault(fmt.Sprintf("Reinstall version %s?", latestVersion), false)
 		if err != nil {
 			return shared.FormatError("failed to read confirmation")
@@ -116,8 +146,8 @@ func RunUpgrade(args []string) error {
 	}
 
 	if !confirmed {
-		fmt.Printf("%sSkipped upgrade%s\n", shared.ColorYellow, shared.ColorReset)
-		return nil
+		shared.Printf("%sSkipped upgrade%s\n", shared.ColorYellow, shared.ColorReset)
+		return printUpgradeResult(result)
 	}
 
 	// Download and execute install script
@@ -133,21 +163,30 @@ func RunUpgrade(args []string) error {
 	upgradeSpinner.Stop()
 
 	// Execute the install script
-	fmt.Printf("%sRunning installer...%s\n\n", shared.ColorYellow, shared.ColorReset)
+	shared.Printf("%sRunning installer...%s\n\n", shared.ColorYellow, shared.ColorReset)
 
 	cmd := exec.Command("sh", "-c", script)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = shared.Stdout()
+	cmd.Stderr = shared.Stderr()
 	cmd.Stdin = os.Stdin
 
 	if err := cmd.Run(); err != nil {
 		return shared.FormatError(fmt.Sprintf("installation failed: %v", err))
 	}
 
-	fmt.Printf("\n%s✓ Upgrade completed successfully%s\n", shared.ColorGreen, shared.ColorReset)
-	fmt.Printf("%sRun 'lancher --version' to verify the new version%s\n", shared.ColorYellow, shared.ColorReset)
+	shared.Printf("\n%s✓ Upgrade completed successfully%s\n", shared.ColorGreen, shared.ColorReset)
+	shared.Printf("%sRun 'lancher --version' to verify the new version%s\n", shared.ColorYellow, shared.ColorReset)
 
-	return nil
+	result.Upgraded = true
+	return printUpgradeResult(result)
+}
+
+// printUpgradeResult prints the outcome of an upgrade in JSON output
+func printUpgradeResult(result upgradeResult) error {
+	if !shared.JSONOutput() {
+		return nil
+	}
+	return shared.PrintJSON(result)
 }
 
 // getLatestRelease fetches the latest release from GitHub API
@@ -0,0 +1,153 @@
+package shared
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+)
+
+// Output formats selected with the global --output flag
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// Error codes reported in JSON error objects
+const (
+	ErrCodeGeneric           = "error"
+	ErrCodeUnknownCommand    = "unknown_command"
+	ErrCodeUnknownSubcommand = "unknown_subcommand"
+	ErrCodeMissingArgument   = "missing_argument"
+	ErrCodeInvalidArgument   = "invalid_argument"
+	ErrCodeTemplateNotFound  = "template_not_found"
+	ErrCodeReadOnlyTemplate  = "read_only_template"
+	ErrCodeDestinationExists = "destination_exists"
+	ErrCodeCancelled         = "cancelled"
+)
+
+var outputFormat = OutputText
+
+// Streams written by commands; see SetOutput
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// ansiCodes matches the color codes stripped from JSON error messages
+var ansiCodes = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// SetOutputFormat selects the output format
+func SetOutputFormat(format string) error {
+	switch format {
+	case OutputText, OutputJSON:
+	default:
+		return fmt.Errorf("invalid output format '%s' (expected %s or %s)", format, OutputText, OutputJSON)
+	}
+	outputFormat = format
+	return nil
+}
+
+// SetOutput redirects the standard output and error streams of commands
+func SetOutput(out, errOut io.Writer) {
+	stdout, stderr = out, errOut
+}
+
+// Stdout returns the writer for human-readable output
+// In JSON mode stdout is reserved for the JSON document, so progress and other
+// human-readable output goes to stderr and still shows without breaking parsers
+func Stdout() io.Writer {
+	if JSONOutput() {
+		return stderr
+	}
+	return stdout
+}
+
+// Stderr returns the writer for diagnostics
+func Stderr() io.Writer {
+	return stderr
+}
+
+// Printf writes human-readable output to Stdout
+func Printf(format string, a ...any) {
+	fmt.Fprintf(Stdout(), format, a...)
+}
+
+// Println writes human-readable output to Stdout, followed by a newline
+func Println(a ...any) {
+	fmt.Fprintln(Stdout(), a...)
+}
+
+// Print writes human-readable output to Stdout
+func Print(a ...any) {
+	fmt.Fprint(Stdout(), a...)
+}
+
+// JSONOutput reports whether results are printed as JSON
+func JSONOutput() bool {
+	return outputFormat == OutputJSON
+}
+
+// PrintJSON writes v as an indented JSON document to stdout
+func PrintJSON(v any) error {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// CommandError is an error with a code identifying its kind in JSON output
+// Error returns the styled text shown to humans; Message is the same text without styling
+type CommandError struct {
+	Code    string
+	Message string
+	text    string
+}
+
+// Error returns the styled error text
+func (e *CommandError) Error() string {
+	return e.text
+}
+
+// newCommandError creates a CommandError with a plain message and its styled text
+func newCommandError(code, message, text string) error {
+	return &CommandError{Code: code, Message: message, text: text}
+}
+
+// FormatErrorCode creates a user-friendly error message with a specific error code
+func FormatErrorCode(code, message string) error {
+	return newCommandError(code, message, fmt.Sprintf("%s✗%s %s", ColorRed, ColorReset, message))
+}
+
+// FormatNotFoundError creates the error reported for a missing template
+func FormatNotFoundError(name string) error {
+	return FormatErrorCode(ErrCodeTemplateNotFound, fmt.Sprintf("template '%s' not found", name))
+}
+
+// FormatReadOnlyError creates the error reported when a command would change a template
+// found outside the user templates directory
+func FormatReadOnlyError(name, scope, root string) error {
+	return FormatErrorCode(ErrCodeReadOnlyTemplate, fmt.Sprintf("template '%s' is a %s template (%s) and cannot be changed by lancher", name, scope, root))
+}
+
+// PrintError prints an error returned by a command
+// In JSON mode it is printed to stdout as {"error": {"code": ..., "message": ...}}
+func PrintError(err error) {
+	if !JSONOutput() {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return
+	}
+
+	code, message := ErrCodeGeneric, ansiCodes.ReplaceAllString(err.Error(), "")
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		code, message = cmdErr.Code, cmdErr.Message
+	}
+	PrintJSON(map[string]any{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
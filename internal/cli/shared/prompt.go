@@ -37,13 +37,13 @@ func restoreTerminal(fd int) error {
 
 // readKey reads a single key or arrow key sequence
 func readKey() (string, error) {
-	buf := make([]byte, 3)
+	buf := make([]byte, 8)
 	n, err := os.Stdin.Read(buf)
 	if err != nil {
 		return "", err
 	}
 
-	// Check for escape sequences (arrow keys)
+	// Check for escape sequences (arrow and navigation keys)
 	if n == 3 && buf[0] == 27 && buf[1] == 91 {
 		switch buf[2] {
 		case 65:
@@ -54,6 +54,22 @@ func readKey() (string, error) {
 			return "right", nil
 		case 68:
 			return "left", nil
+		case 72:
+			return "home", nil
+		case 70:
+			return "end", nil
+		}
+	}
+	if n == 4 && buf[0] == 27 && buf[1] == 91 && buf[3] == 126 {
+		switch buf[2] {
+		case 53:
+			return "pgup", nil
+		case 54:
+			return "pgdown", nil
+		case 49, 55:
+			return "home", nil
+		case 52, 56:
+			return "end", nil
 		}
 	}
 
@@ -68,6 +84,10 @@ func readKey() (string, error) {
 			return "q", nil
 		case 32: // Space
 			return "space", nil
+		case 127, 8: // Backspace
+			return "backspace", nil
+		case 27: // Escape
+			return "esc", nil
 		}
 	}
 
@@ -76,7 +96,7 @@ func readKey() (string, error) {
 
 // PromptString prompts for a text input
 func PromptString(prompt string) (string, error) {
-	fmt.Printf("%s%s%s ", ColorCyan, prompt, ColorReset)
+	Printf("%s%s%s ", ColorCyan, prompt, ColorReset)
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
 	if err != nil {
@@ -87,13 +107,13 @@ func PromptString(prompt string) (string, error) {
 
 // PromptStringWithDefault prompts for text input with a default value shown as gray placeholder
 func PromptStringWithDefault(prompt, defaultValue string) (string, error) {
-	fmt.Printf("%s%s%s ", ColorCyan, prompt, ColorReset)
+	Printf("%s%s%s ", ColorCyan, prompt, ColorReset)
 
 	// Check if terminal supports raw mode for interactive placeholder
 	fd := int(os.Stdin.Fd())
 	if !isTerminal(fd) {
 		// Fallback: simple prompt without placeholder
-		fmt.Printf("(default: %s%s%s) ", ColorGray, defaultValue, ColorReset)
+		Printf("(default: %s%s%s) ", ColorGray, defaultValue, ColorReset)
 		reader := bufio.NewReader(os.Stdin)
 		input, err := reader.ReadString('\n')
 		if err != nil {
@@ -110,7 +130,7 @@ func PromptStringWithDefault(prompt, defaultValue string) (string, error) {
 	state, err := term.MakeRaw(fd)
 	if err != nil {
 		// Fallback on error
-		fmt.Printf("(default: %s%s%s) ", ColorGray, defaultValue, ColorReset)
+		Printf("(default: %s%s%s) ", ColorGray, defaultValue, ColorReset)
 		reader := bufio.NewReader(os.Stdin)
 		input, err := reader.ReadString('\n')
 		if err != nil {
@@ -125,10 +145,10 @@ func PromptStringWithDefault(prompt, defaultValue string) (string, error) {
 	defer term.Restore(fd, state)
 
 	// Print placeholder in gray at cursor position
-	fmt.Printf("%s%s%s", ColorGray, defaultValue, ColorReset)
+	Printf("%s%s%s", ColorGray, defaultValue, ColorReset)
 	// Move cursor back to start of placeholder
 	for i := 0; i < len(defaultValue); i++ {
-		fmt.Print("\033[D")
+		Print("\033[D")
 	}
 
 	var inputBuffer []rune
@@ -152,9 +172,9 @@ func PromptStringWithDefault(prompt, defaultValue string) (string, error) {
 		if b == 13 || b == 10 {
 			// Clear placeholder if still visible
 			if placeholderVisible {
-				fmt.Print("\033[K")
+				Print("\033[K")
 			}
-			fmt.Println() // Move to next line
+			Println() // Move to next line
 			break
 		}
 
@@ -167,17 +187,17 @@ func PromptStringWithDefault(prompt, defaultValue string) (string, error) {
 				if len(inputBuffer) == 0 {
 					// Input is now empty - show placeholder again
 					// Move back one position, then clear to end of line and show placeholder
-					fmt.Print("\033[D")  // Move cursor back
-					fmt.Print("\033[K")  // Clear to end of line
-					fmt.Printf("%s%s%s", ColorGray, defaultValue, ColorReset)
+					Print("\033[D")  // Move cursor back
+					Print("\033[K")  // Clear to end of line
+					Printf("%s%s%s", ColorGray, defaultValue, ColorReset)
 					// Move cursor back to start of placeholder
 					for i := 0; i < len(defaultValue); i++ {
-						fmt.Print("\033[D")
+						Print("\033[D")
 					}
 					placeholderVisible = true
 				} else {
 					// Move back and clear character
-					fmt.Print("\033[D \033[D")
+					Print("\033[D \033[D")
 				}
 			}
 			continue
@@ -186,7 +206,7 @@ func PromptStringWithDefault(prompt, defaultValue string) (string, error) {
 		// Handle Ctrl+C
 		if b == 3 {
 			// Clear current line content and move cursor to beginning
-			fmt.Print("\r\033[K")
+			Print("\r\033[K")
 			term.Restore(fd, state)
 			return "", fmt.Errorf("cancelled by user")
 		}
@@ -199,20 +219,20 @@ func PromptStringWithDefault(prompt, defaultValue string) (string, error) {
 		// Regular character input
 		if placeholderVisible {
 			// Clear placeholder on first character
-			fmt.Print("\033[K") // Clear to end of line
+			Print("\033[K") // Clear to end of line
 			placeholderVisible = false
 		}
 
 		// Add to buffer and display
 		r := rune(b)
 		inputBuffer = append(inputBuffer, r)
-		fmt.Printf("%c", r)
+		Printf("%c", r)
 	}
 
 	input := string(inputBuffer)
 
 	// Go back to start of line and clear it, then go up one line and clear that too
-	fmt.Print("\r\033[K\033[1A\033[K")
+	Print("\r\033[K\033[1A\033[K")
 
 	// If empty, use default
 	if input == "" {
@@ -224,7 +244,7 @@ func PromptStringWithDefault(prompt, defaultValue string) (string, error) {
 
 // PromptConfirm prompts for yes/no confirmation
 func PromptConfirm(prompt string) (bool, error) {
-	fmt.Printf("%s%s (y/n):%s ", ColorYellow, prompt, ColorReset)
+	Printf("%s%s (y/n):%s ", ColorYellow, prompt, ColorReset)
 
 	fd := int(os.Stdin.Fd())
 	if !isTerminal(fd) {
@@ -267,7 +287,7 @@ func PromptConfirm(prompt string) (bool, error) {
 
 		// Handle Ctrl+C
 		if b == 3 {
-			fmt.Print("\r\n")
+			Print("\r\n")
 			term.Restore(fd, state)
 			return false, fmt.Errorf("cancelled by user")
 		}
@@ -276,10 +296,10 @@ func PromptConfirm(prompt string) (bool, error) {
 		ch := strings.ToLower(string(b))
 
 		if ch == "y" {
-			fmt.Print("y\r\n")
+			Print("y\r\n")
 			return true, nil
 		} else if ch == "n" {
-			fmt.Print("n\r\n")
+			Print("n\r\n")
 			return false, nil
 		}
 		// Ignore other characters and wait for y or n
@@ -297,7 +317,7 @@ func PromptConfirmWithDefault(prompt string, defaultValue bool) (bool, error) {
 		defaultChar = "n"
 	}
 
-	fmt.Printf("%s%s (%s):%s ", ColorYellow, prompt, placeholder, ColorReset)
+	Printf("%s%s (%s):%s ", ColorYellow, prompt, placeholder, ColorReset)
 
 	fd := int(os.Stdin.Fd())
 	if !isTerminal(fd) {
@@ -315,16 +335,16 @@ func PromptConfirmWithDefault(prompt string, defaultValue bool) (bool, error) {
 	}
 
 	// Print placeholder in gray
-	fmt.Printf("%s%s%s", ColorGray, defaultChar, ColorReset)
+	Printf("%s%s%s", ColorGray, defaultChar, ColorReset)
 	// Move cursor back
-	fmt.Printf("\033[1D")
+	Printf("\033[1D")
 
 	// Set terminal to raw mode for character-by-character reading
 	state, err := term.MakeRaw(fd)
 	if err != nil {
 		// Fallback on error
-		fmt.Print("\r\033[K") // Clear line
-		fmt.Printf("%s%s (%s):%s ", ColorYellow, prompt, placeholder, ColorReset)
+		Print("\r\033[K") // Clear line
+		Printf("%s%s (%s):%s ", ColorYellow, prompt, placeholder, ColorReset)
 		reader := bufio.NewReader(os.Stdin)
 		input, err := reader.ReadString('\n')
 		if err != nil {
@@ -353,15 +373,15 @@ func PromptConfirmWithDefault(prompt string, defaultValue bool) (bool, error) {
 
 		// Handle Enter key - use default
 		if b == 13 || b == 10 {
-			fmt.Print("\033[K") // Clear placeholder
-			fmt.Printf("%s\r\n", defaultChar)
+			Print("\033[K") // Clear placeholder
+			Printf("%s\r\n", defaultChar)
 			return defaultValue, nil
 		}
 
 		// Handle Ctrl+C
 		if b == 3 {
-			fmt.Print("\033[K") // Clear placeholder
-			fmt.Print("\r\n")
+			Print("\033[K") // Clear placeholder
+			Print("\r\n")
 			term.Restore(fd, state)
 			return false, fmt.Errorf("cancelled by user")
 		}
@@ -370,12 +390,12 @@ func PromptConfirmWithDefault(prompt string, defaultValue bool) (bool, error) {
 		ch := strings.ToLower(string(b))
 
 		if ch == "y" {
-			fmt.Print("\033[K") // Clear placeholder
-			fmt.Print("y\r\n")
+			Print("\033[K") // Clear placeholder
+			Print("y\r\n")
 			return true, nil
 		} else if ch == "n" {
-			fmt.Print("\033[K") // Clear placeholder
-			fmt.Print("n\r\n")
+			Print("\033[K") // Clear placeholder
+			Print("n\r\n")
 			return false, nil
 		}
 		// Ignore other characters and wait for y, n or Enter
@@ -0,0 +1,163 @@
+package shared
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FormatSize returns a human-readable size (e.g. "1.5 MB")
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// treeNode is a directory or file in a tree built by FormatTree
+type treeNode struct {
+	name     string
+	children []*treeNode
+	index    map[string]*treeNode
+}
+
+// child returns the child node with the given name, creating it if needed
+func (n *treeNode) child(name string) *treeNode {
+	if c, ok := n.index[name]; ok {
+		return c
+	}
+	c := &treeNode{name: name, index: make(map[string]*treeNode)}
+	n.children = append(n.children, c)
+	n.index[name] = c
+	return c
+}
+
+// FormatTree renders slash-separated file paths as an indented tree, one line per entry
+// Entries keep the order of paths; directories are suffixed with "/"
+func FormatTree(paths []string) []string {
+	root := &treeNode{index: make(map[string]*treeNode)}
+	for _, path := range paths {
+		node := root
+		for _, part := range strings.Split(path, "/") {
+			node = node.child(part)
+		}
+	}
+
+	var lines []string
+	var walk func(node *treeNode, prefix string)
+	walk = func(node *treeNode, prefix string) {
+		for i, c := range node.children {
+			branch, indent := "├── ", "│   "
+			if i == len(node.children)-1 {
+				branch, indent = "└── ", "    "
+			}
+			name := c.name
+			if len(c.children) > 0 {
+				name += "/"
+			}
+			lines = append(lines, prefix+branch+name)
+			walk(c, prefix+indent)
+		}
+	}
+	walk(root, "")
+	return lines
+}
+
+// Inline markdown elements handled by RenderMarkdown
+var (
+	markdownCode   = regexp.MustCompile("`([^`]+)`")
+	markdownBold   = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	markdownLink   = regexp.MustCompile(`!?\[([^\]]*)\]\(([^)\s]+)[^)]*\)`)
+	markdownList   = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+	markdownRule   = regexp.MustCompile(`^\s*(-(\s*-){2,}|\*(\s*\*){2,}|_(\s*_){2,})\s*$`)
+	markdownHeader = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+)
+
+// RenderMarkdown renders markdown for the terminal
+// Headings, lists, quotes, code, emphasis and links are styled; everything else is kept as is
+func RenderMarkdown(text string) string {
+	var out strings.Builder
+	inCode := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		// Fenced code blocks are indented and dimmed
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out.WriteString("    " + ColorGray + line + ColorReset + "\n")
+			continue
+		}
+
+		switch {
+		case markdownHeader.MatchString(line):
+			m := markdownHeader.FindStringSubmatch(line)
+			style := ColorBold
+			if len(m[1]) == 1 {
+				style = ColorCyan + ColorBold
+			}
+			out.WriteString(style + renderInline(m[2], style) + ColorReset + "\n")
+		case markdownRule.MatchString(line):
+			out.WriteString(ColorGray + strings.Repeat("─", 40) + ColorReset + "\n")
+		case strings.HasPrefix(trimmed, ">"):
+			quote := strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+			out.WriteString(ColorGray + "│ " + ColorReset + renderInline(quote, "") + "\n")
+		case markdownList.MatchString(line):
+			indent := markdownList.FindStringSubmatch(line)[1]
+			item := markdownList.ReplaceAllString(line, "")
+			out.WriteString(indent + "• " + renderInline(item, "") + "\n")
+		default:
+			out.WriteString(renderInline(line, "") + "\n")
+		}
+	}
+
+	return strings.TrimRight(out.String(), "\n")
+}
+
+// renderInline styles inline code, bold text and links
+// style is restored after each styled span so it can be used inside headings
+func renderInline(text, style string) string {
+	reset := ColorReset + style
+	text = markdownLink.ReplaceAllStringFunc(text, func(s string) string {
+		m := markdownLink.FindStringSubmatch(s)
+		if m[1] == "" || m[1] == m[2] {
+			return ColorBlue + m[2] + reset
+		}
+		return m[1] + " " + ColorGray + "(" + m[2] + ")" + reset
+	})
+	text = markdownCode.ReplaceAllString(text, ColorCyan+"$1"+reset)
+	text = markdownBold.ReplaceAllString(text, ColorBold+"$1$2"+reset)
+	return text
+}
+
+// HighlightMatches styles the runes of text at the given ascending positions (see fuzzy.Result)
+func HighlightMatches(text string, positions []int, style string) string {
+	if len(positions) == 0 {
+		return text
+	}
+
+	var out strings.Builder
+	next := 0
+	for i, r := range []rune(text) {
+		matched := false
+		for next < len(positions) && positions[next] <= i {
+			matched = matched || positions[next] == i
+			next++
+		}
+		if matched {
+			out.WriteString(style + string(r) + ColorReset)
+		} else {
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
@@ -6,18 +6,119 @@ import (
 	"os"
 	"strconv"
 	"strings"
+
+	"golang.org/x/term"
 )
 
 // SelectOption represents an option in the selection menu
 type SelectOption struct {
-	Value string
-	Label string // If empty, Value is used as label
+	Value       string
+	Label       string // If empty, Value is used as label
+	Group       string // Consecutive options with the same group, ignoring case, are listed under a common header
+	Description string // Shown below the list while the option is selected
+}
+
+// ungroupedHeader is shown for options without a group when other options have one
+const ungroupedHeader = "Other"
+
+// groupHeader returns the header to print before option i, if any
+// Headers are only shown when at least one option has a group
+func groupHeader(options []SelectOption, i int) (string, bool) {
+	if !hasGroups(options) {
+		return "", false
+	}
+	if i > 0 && strings.EqualFold(options[i-1].Group, options[i].Group) {
+		return "", false
+	}
+	if options[i].Group == "" {
+		return ungroupedHeader, true
+	}
+	return options[i].Group, true
+}
+
+// hasGroups reports whether any option has a group
+func hasGroups(options []SelectOption) bool {
+	for _, opt := range options {
+		if opt.Group != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// countLines returns the number of lines used to render options, including group headers
+func countLines(options []SelectOption) int {
+	lines := len(options)
+	for i := range options {
+		if _, ok := groupHeader(options, i); ok {
+			lines++
+		}
+	}
+	return lines
+}
+
+// newTerminalSelector prepares a Selector sized to the terminal on fd
+func newTerminalSelector(fd int, prompt, hint string, indent int, options []SelectOption) *Selector {
+	width, height, err := term.GetSize(fd)
+	if err != nil {
+		width, height = 0, 0
+	}
+	return NewSelector(prompt, hint, indent, width, height, options)
+}
+
+// renderSelector draws the block of s and moves the cursor back to its first line
+// line formats an option given its index and its highlighted label
+func renderSelector(s *Selector, line func(index int, label string, current bool) string) {
+	if s.query == "" {
+		Printf("\r\033[K%s%s%s %s(type to filter)%s\n", ColorCyan, s.prompt, ColorReset, ColorGray, ColorReset)
+	} else {
+		Printf("\r\033[K%s%s%s %s%s\n", ColorCyan, s.prompt, ColorReset, ColorBold+s.query, ColorReset)
+	}
+	if s.hint != "" {
+		Printf("\r\033[K%s%s%s\n", ColorGray, s.hint, ColorReset)
+	}
+
+	view := s.View()
+	printed := 0
+	for _, row := range view.Rows {
+		if row.Option < 0 {
+			Printf("\r\033[K%s%s%s\n", ColorBold, row.Header, ColorReset)
+		} else {
+			label := HighlightMatches(row.Label, row.Positions, ColorYellow)
+			Printf("\r\033[K%s\n", line(row.Option, label, row.Current))
+		}
+		printed++
+	}
+	if len(view.Rows) == 0 {
+		Printf("\r\033[K%sNo matches%s\n", ColorGray, ColorReset)
+		printed++
+	}
+	for ; printed < s.height; printed++ {
+		Print("\r\033[K\n")
+	}
+
+	if s.paged {
+		Printf("\r\033[K  %s%s%s\n", ColorGray, view.Position, ColorReset)
+	}
+	if s.preview {
+		Printf("\r\033[K  %s%s%s\n", ColorGray, view.Preview, ColorReset)
+	}
+
+	Printf("\033[%dA", s.lines())
+}
+
+// clearSelector erases the block of s, leaving the cursor on its first line
+func clearSelector(s *Selector) {
+	for i := 0; i < s.lines(); i++ {
+		Print("\r\033[K\n")
+	}
+	Printf("\033[%dA", s.lines())
 }
 
 // selectWithArrows provides interactive selection with arrow key navigation
+// Typing filters the options with fuzzy matching; matches are listed best first
 func selectWithArrows(prompt string, options []SelectOption) (string, error) {
 	fd := int(os.Stdin.Fd())
-	selected := 0
 
 	// Set terminal to raw mode
 	if err := setRawMode(fd); err != nil {
@@ -27,19 +128,17 @@ func selectWithArrows(prompt string, options []SelectOption) (string, error) {
 	defer restoreTerminal(fd)
 
 	// Hide cursor and show initial selection
-	fmt.Print("\033[?25l")
-	defer fmt.Print("\033[?25h")
+	Print("\033[?25l")
+	defer Print("\033[?25h")
 
+	s := newTerminalSelector(fd, prompt, "", 2, options)
 	renderOptions := func() {
-		fmt.Printf("\r\033[K%s%s%s\n", ColorCyan, prompt, ColorReset)
-		for i, opt := range options {
-			if i == selected {
-				fmt.Printf("\r\033[K%s>%s %s\n", ColorGreen, ColorReset, opt.Label)
-			} else {
-				fmt.Printf("\r\033[K%s•%s %s\n", ColorGray, ColorReset, opt.Label)
+		renderSelector(s, func(index int, label string, current bool) string {
+			if current {
+				return fmt.Sprintf("%s>%s %s", ColorGreen, ColorReset, label)
 			}
-		}
-		fmt.Printf("\033[%dA", len(options)+1)
+			return fmt.Sprintf("%s•%s %s", ColorGray, ColorReset, label)
+		})
 	}
 
 	renderOptions()
@@ -51,41 +150,47 @@ func selectWithArrows(prompt string, options []SelectOption) (string, error) {
 		}
 
 		switch key {
-		case "up":
-			if selected > 0 {
-				selected--
-				renderOptions()
-			}
-		case "down":
-			if selected < len(options)-1 {
-				selected++
-				renderOptions()
-			}
 		case "enter":
-			// Clear the display
-			for i := 0; i <= len(options); i++ {
-				fmt.Print("\r\033[K\n")
+			current := s.Current()
+			if current < 0 {
+				continue
 			}
-			fmt.Printf("\033[%dA", len(options)+1)
-			return options[selected].Value, nil
-		case "ctrl+c", "q":
-			// Clear the display
-			for i := 0; i <= len(options); i++ {
-				fmt.Print("\r\033[K\n")
+			clearSelector(s)
+			return options[current].Value, nil
+		case "esc":
+			// Escape clears the filter first, then cancels
+			if s.query != "" {
+				s.Filter("")
+				renderOptions()
+				continue
 			}
-			fmt.Printf("\033[%dA", len(options)+1)
+			clearSelector(s)
+			return "", fmt.Errorf("cancelled")
+		case "ctrl+c":
+			clearSelector(s)
 			return "", fmt.Errorf("cancelled")
+		default:
+			if s.HandleKey(key, true) {
+				renderOptions()
+			}
 		}
 	}
 }
 
 // selectWithNumbers provides numbered selection as fallback
 func selectWithNumbers(prompt string, options []SelectOption) (string, error) {
-	fmt.Println(prompt)
+	Println(prompt)
 	for i, opt := range options {
-		fmt.Printf("  %d) %s\n", i+1, opt.Label)
+		if header, ok := groupHeader(options, i); ok {
+			Printf("%s:\n", header)
+		}
+		if opt.Description != "" {
+			Printf("  %d) %s %s- %s%s\n", i+1, opt.Label, ColorGray, opt.Description, ColorReset)
+		} else {
+			Printf("  %d) %s\n", i+1, opt.Label)
+		}
 	}
-	fmt.Print("Enter number: ")
+	Print("Enter number: ")
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
@@ -148,6 +253,7 @@ func SelectWithOptions(prompt string, options []SelectOption) (string, error) {
 }
 
 // MultiSelect prompts user to select multiple options with arrow key navigation and space bar to toggle
+// Typing filters the choices; marks are kept while filtering
 // Returns a slice of selected values or error if cancelled
 func MultiSelect(prompt string, choices []string) ([]string, error) {
 	if len(choices) == 0 {
@@ -167,27 +273,31 @@ func MultiSelect(prompt string, choices []string) ([]string, error) {
 	defer restoreTerminal(fd)
 
 	// Hide cursor and show initial selection
-	fmt.Print("\033[?25l")
-	defer fmt.Print("\033[?25h")
+	Print("\033[?25l")
+	defer Print("\033[?25h")
+
+	options := make([]SelectOption, len(choices))
+	for i, choice := range choices {
+		options[i] = SelectOption{
+			Value: choice,
+			Label: choice,
+		}
+	}
 
-	selected := 0
 	marked := make(map[int]bool)
+	s := newTerminalSelector(fd, prompt, "(Use arrows to move, space to toggle, enter to confirm)", 6, options)
 
 	renderOptions := func() {
-		fmt.Printf("\r\033[K%s%s%s\n", ColorCyan, prompt, ColorReset)
-		fmt.Printf("\r\033[K%s(Use arrows to move, space to toggle, enter to confirm)%s\n", ColorGray, ColorReset)
-		for i, choice := range choices {
+		renderSelector(s, func(index int, label string, current bool) string {
 			marker := " "
-			if marked[i] {
+			if marked[index] {
 				marker = "✓"
 			}
-			if i == selected {
-				fmt.Printf("\r\033[K%s>%s [%s%s%s] %s\n", ColorGreen, ColorReset, ColorGreen, marker, ColorReset, choice)
-			} else {
-				fmt.Printf("\r\033[K%s•%s [%s] %s\n", ColorGray, ColorReset, marker, choice)
+			if current {
+				return fmt.Sprintf("%s>%s [%s%s%s] %s", ColorGreen, ColorReset, ColorGreen, marker, ColorReset, label)
 			}
-		}
-		fmt.Printf("\033[%dA", len(choices)+2)
+			return fmt.Sprintf("%s•%s [%s] %s", ColorGray, ColorReset, marker, label)
+		})
 	}
 
 	renderOptions()
@@ -199,25 +309,13 @@ func MultiSelect(prompt string, choices []string) ([]string, error) {
 		}
 
 		switch key {
-		case "up":
-			if selected > 0 {
-				selected--
-				renderOptions()
-			}
-		case "down":
-			if selected < len(choices)-1 {
-				selected++
+		case "space":
+			if current := s.Current(); current >= 0 {
+				marked[current] = !marked[current]
 				renderOptions()
 			}
-		case "space":
-			marked[selected] = !marked[selected]
-			renderOptions()
 		case "enter":
-			// Clear the display
-			for i := 0; i <= len(choices)+1; i++ {
-				fmt.Print("\r\033[K\n")
-			}
-			fmt.Printf("\033[%dA", len(choices)+2)
+			clearSelector(s)
 
 			// Collect marked items
 			var result []string
@@ -227,25 +325,34 @@ func MultiSelect(prompt string, choices []string) ([]string, error) {
 				}
 			}
 			return result, nil
-		case "ctrl+c", "q":
-			// Clear the display
-			for i := 0; i <= len(choices)+1; i++ {
-				fmt.Print("\r\033[K\n")
+		case "esc":
+			// Escape clears the filter first, then cancels
+			if s.query != "" {
+				s.Filter("")
+				renderOptions()
+				continue
 			}
-			fmt.Printf("\033[%dA", len(choices)+2)
+			clearSelector(s)
+			return nil, fmt.Errorf("cancelled")
+		case "ctrl+c":
+			clearSelector(s)
 			return nil, fmt.Errorf("cancelled")
+		default:
+			if s.HandleKey(key, false) {
+				renderOptions()
+			}
 		}
 	}
 }
 
 // multiSelectWithNumbers provides numbered multi-selection as fallback
 func multiSelectWithNumbers(prompt string, choices []string) ([]string, error) {
-	fmt.Println(prompt)
-	fmt.Println("(Enter numbers separated by commas, e.g., 1,3,5)")
+	Println(prompt)
+	Println("(Enter numbers separated by commas, e.g., 1,3,5)")
 	for i, choice := range choices {
-		fmt.Printf("  %d) %s\n", i+1, choice)
+		Printf("  %d) %s\n", i+1, choice)
 	}
-	fmt.Print("Enter numbers: ")
+	Print("Enter numbers: ")
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
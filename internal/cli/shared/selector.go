@@ -0,0 +1,281 @@
+package shared
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/lancher-dev/lancher/internal/fuzzy"
+)
+
+// defaultTerminalHeight is assumed when the terminal size cannot be read
+const defaultTerminalHeight = 24
+
+// minViewportRows is the smallest number of rows shown when the list is paged
+const minViewportRows = 3
+
+// listRow is a line of the option list: a group header or an option
+type listRow struct {
+	header string
+	match  int // Index into Selector.matches, or -1 for a header
+}
+
+// SelectorRow is a visible line of the option list: a group header or an option
+type SelectorRow struct {
+	Header    string // Group header, empty for an option
+	Option    int    // Index into the options, or -1 for a header
+	Label     string // Option label, truncated to the terminal width
+	Positions []int  // Runes of Label matched by the query
+	Current   bool
+}
+
+// SelectorView describes the block drawn for a Selector
+type SelectorView struct {
+	Rows     []SelectorRow // Rows inside the viewport; empty when nothing matches
+	Position string        // Position line of a paged list, empty otherwise
+	Preview  string        // Description of the current option, when any option has one
+}
+
+// Selector holds the state of an interactive list filtered by typing
+// It does no terminal I/O: keys are applied with HandleKey and the block to draw is
+// described by View. The block has a fixed height: the prompt, an optional hint,
+// a scrolling viewport of options, a position line when the list is paged and
+// a preview of the current option's description when any option has one
+type Selector struct {
+	prompt  string
+	hint    string
+	options []SelectOption
+	labels  []string
+
+	query    string
+	matches  []fuzzy.Ranked
+	selected int // Index into matches
+	offset   int // First row of the viewport
+
+	height  int // Rows in the viewport
+	paged   bool
+	preview bool
+	width   int
+	indent  int // Columns printed before each label
+}
+
+// NewSelector prepares a selector for a terminal of width columns and height rows
+// A height of 0 assumes defaultTerminalHeight and a width of 0 disables truncation
+// indent is the width of the marker printed before each label
+func NewSelector(prompt, hint string, indent, width, height int, options []SelectOption) *Selector {
+	s := &Selector{prompt: prompt, hint: hint, indent: indent, width: width, options: options}
+
+	s.labels = make([]string, len(options))
+	for i, opt := range options {
+		s.labels[i] = opt.Label
+		if opt.Description != "" {
+			s.preview = true
+		}
+	}
+	s.matches = fuzzy.Rank("", s.labels)
+
+	if height <= 0 {
+		height = defaultTerminalHeight
+	}
+
+	// Filtered lists have no group headers, so the unfiltered list is the tallest
+	lines := countLines(options)
+	chrome := 1 + 1 // Prompt, and the line the cursor is left on
+	if hint != "" {
+		chrome++
+	}
+	if s.preview {
+		chrome++
+	}
+	if available := height - chrome; lines > available {
+		s.paged = true
+		s.height = max(available-1, minViewportRows) // One more line for the position
+	} else {
+		s.height = lines
+	}
+
+	return s
+}
+
+// lines returns the height of the drawn block
+func (s *Selector) lines() int {
+	lines := 1 + s.height
+	if s.hint != "" {
+		lines++
+	}
+	if s.paged {
+		lines++
+	}
+	if s.preview {
+		lines++
+	}
+	return lines
+}
+
+// Query returns the current filter
+func (s *Selector) Query() string {
+	return s.query
+}
+
+// Current returns the index of the current option, or -1 if nothing matches
+func (s *Selector) Current() int {
+	if len(s.matches) == 0 {
+		return -1
+	}
+	return s.matches[s.selected].Index
+}
+
+// Filter applies a new query and moves back to the best match
+func (s *Selector) Filter(query string) {
+	s.query = query
+	s.matches = fuzzy.Rank(query, s.labels)
+	s.selected = 0
+	s.offset = 0
+}
+
+// rows returns the lines of the list; group headers are only shown unfiltered
+func (s *Selector) rows() []listRow {
+	var rows []listRow
+	for i, match := range s.matches {
+		if s.query == "" {
+			if header, ok := groupHeader(s.options, match.Index); ok {
+				rows = append(rows, listRow{header: header, match: -1})
+			}
+		}
+		rows = append(rows, listRow{match: i})
+	}
+	return rows
+}
+
+// move moves the selection by delta options, stopping at either end, and scrolls
+// the viewport to it
+func (s *Selector) move(delta int) {
+	s.selected = max(0, min(s.selected+delta, len(s.matches)-1))
+	s.scroll(s.rows())
+}
+
+// scroll adjusts the viewport so the current option, and its group header when
+// moving up, are visible
+func (s *Selector) scroll(rows []listRow) {
+	row := 0
+	for i, r := range rows {
+		if r.match == s.selected {
+			row = i
+			break
+		}
+	}
+	if row > 0 && rows[row-1].match < 0 {
+		row--
+		if row < s.offset {
+			s.offset = row
+		}
+		row++
+	}
+	if row < s.offset {
+		s.offset = row
+	}
+	if row >= s.offset+s.height {
+		s.offset = row - s.height + 1
+	}
+	s.offset = max(0, min(s.offset, len(rows)-s.height))
+}
+
+// HandleKey applies navigation and filtering keys
+// typeSpace controls whether space is typed into the filter
+// Returns false for keys the caller handles
+func (s *Selector) HandleKey(key string, typeSpace bool) bool {
+	switch key {
+	case "up":
+		s.move(-1)
+	case "down":
+		s.move(1)
+	case "pgup":
+		s.move(-max(s.height-1, 1))
+	case "pgdown":
+		s.move(max(s.height-1, 1))
+	case "home":
+		s.move(-len(s.matches))
+	case "end":
+		s.move(len(s.matches))
+	case "left", "right":
+	case "backspace":
+		if s.query != "" {
+			runes := []rune(s.query)
+			s.Filter(string(runes[:len(runes)-1]))
+		}
+	case "space":
+		if !typeSpace {
+			return false
+		}
+		if s.query != "" {
+			s.Filter(s.query + " ")
+		}
+	default:
+		if !isPrintable(key) {
+			return false
+		}
+		s.Filter(s.query + key)
+	}
+	return true
+}
+
+// View returns the block to draw
+func (s *Selector) View() SelectorView {
+	var view SelectorView
+
+	rows := s.rows()
+	for _, row := range rows[s.offset:min(s.offset+s.height, len(rows))] {
+		if row.match < 0 {
+			view.Rows = append(view.Rows, SelectorRow{Header: row.header, Option: -1})
+			continue
+		}
+		match := s.matches[row.match]
+		view.Rows = append(view.Rows, SelectorRow{
+			Option:    match.Index,
+			Label:     s.truncate(s.labels[match.Index], s.indent),
+			Positions: match.Positions,
+			Current:   row.match == s.selected,
+		})
+	}
+
+	if s.paged {
+		view.Position = fmt.Sprintf("%d/%d", min(s.selected+1, len(s.matches)), len(s.matches))
+		if s.offset > 0 {
+			view.Position += " ↑"
+		}
+		if s.offset+s.height < len(rows) {
+			view.Position += " ↓"
+		}
+		if len(rows) > s.height {
+			view.Position += " (PgUp/PgDn to page)"
+		}
+	}
+	if s.preview {
+		if current := s.Current(); current >= 0 {
+			view.Preview = s.truncate(s.options[current].Description, 2)
+		}
+	}
+	return view
+}
+
+// truncate shortens text to fit the terminal after indent columns, so lines never wrap
+func (s *Selector) truncate(text string, indent int) string {
+	limit := s.width - indent - 1
+	if limit < 1 || utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit-1]) + "…"
+}
+
+// isPrintable reports whether a key read by readKey is text that can be typed into a filter
+func isPrintable(key string) bool {
+	if !utf8.ValidString(key) {
+		return false
+	}
+	for _, r := range key {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return key != ""
+}
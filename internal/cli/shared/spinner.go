@@ -2,9 +2,7 @@ package shared
 
 import (
 	"bytes"
-	"fmt"
 	"io"
-	"os"
 	"sync"
 	"time"
 )
@@ -47,10 +45,10 @@ func (s *Spinner) Start() {
 			select {
 			case <-s.stop:
 				// Clear the line
-				fmt.Printf("\r\033[K")
+				Printf("\r\033[K")
 				return
 			default:
-				fmt.Printf("\r%s%s%s %s", ColorYellow, s.frames[i%len(s.frames)], ColorReset, s.message)
+				Printf("\r%s%s%s %s", ColorYellow, s.frames[i%len(s.frames)], ColorReset, s.message)
 				i++
 				time.Sleep(100 * time.Millisecond)
 			}
@@ -75,13 +73,13 @@ func (s *Spinner) Stop() {
 // Success stops the spinner and shows success message
 func (s *Spinner) Success(message string) {
 	s.Stop()
-	fmt.Printf("%s✓%s %s\n", ColorGreen, ColorReset, message)
+	Printf("%s✓%s %s\n", ColorGreen, ColorReset, message)
 }
 
 // Fail stops the spinner and shows error message
 func (s *Spinner) Fail(message string) {
 	s.Stop()
-	fmt.Printf("%s✗%s %s\n", ColorRed, ColorReset, message)
+	Printf("%s✗%s %s\n", ColorRed, ColorReset, message)
 }
 
 // SpinnerWriter wraps an io.Writer to suppress output during spinner
@@ -101,7 +99,7 @@ func NewSpinnerWriter(verbose bool) *SpinnerWriter {
 // Write implements io.Writer
 func (w *SpinnerWriter) Write(p []byte) (n int, err error) {
 	if w.verbose {
-		return os.Stdout.Write(p)
+		return Stdout().Write(p)
 	}
 	return w.buffer.Write(p)
 }
@@ -114,7 +112,7 @@ func (w *SpinnerWriter) GetOutput() string {
 // MultiWriter returns stdout in verbose mode, buffer otherwise
 func (w *SpinnerWriter) MultiWriter() io.Writer {
 	if w.verbose {
-		return os.Stdout
+		return Stdout()
 	}
 	return w.buffer
 }
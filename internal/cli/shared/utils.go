@@ -8,7 +8,7 @@ import (
 
 // FormatError creates a user-friendly error message with visual styling
 func FormatError(message string) error {
-	return fmt.Errorf("%s✗%s %s", ColorRed, ColorReset, message)
+	return FormatErrorCode(ErrCodeGeneric, message)
 }
 
 // CommandExists checks if a command is available in PATH
@@ -19,22 +19,22 @@ func CommandExists(cmd string) bool {
 
 // FormatUnknownCommandError creates a formatted error for unknown commands
 func FormatUnknownCommandError(arg string, usage string, helpCmd string) error {
-	return fmt.Errorf("%sUnknown command '%s%s%s'%s\n\n%s\n\nRun %s--help%s for more information",
+	return newCommandError(ErrCodeUnknownCommand, fmt.Sprintf("unknown command '%s'", arg), fmt.Sprintf("%sUnknown command '%s%s%s'%s\n\n%s\n\nRun %s--help%s for more information",
 		ColorRed+ColorBold,
 		ColorYellow, arg, ColorReset,
 		ColorReset,
 		usage,
-		ColorGreen, ColorReset)
+		ColorGreen, ColorReset))
 }
 
 // FormatUnknownSubcommandError creates a formatted error for unknown subcommands
 func FormatUnknownSubcommandError(arg string, parentCmd string, usage string) error {
-	return fmt.Errorf("%sUnknown subcommand '%s%s%s'%s\n\n%s\n\nRun %s%s --help%s for more information",
+	return newCommandError(ErrCodeUnknownSubcommand, fmt.Sprintf("unknown subcommand '%s'", arg), fmt.Sprintf("%sUnknown subcommand '%s%s%s'%s\n\n%s\n\nRun %s%s --help%s for more information",
 		ColorRed+ColorBold,
 		ColorYellow, arg, ColorReset,
 		ColorReset,
 		usage,
-		ColorGreen, parentCmd, ColorReset)
+		ColorGreen, parentCmd, ColorReset))
 }
 
 // ValidateArgs checks if the correct number of arguments is provided
@@ -65,23 +65,39 @@ func FormatMissingArgsError(missingArgs []string, usage string) error {
 		argsText = strings.TrimSuffix(argsText, "\n")
 	}
 
-	return fmt.Errorf("%sThe following required arguments were not provided:%s\n%s\n\n%s\n\nRun %s--help%s for more information",
+	message := "missing required arguments: <" + strings.Join(missingArgs, ">, <") + ">"
+	return newCommandError(ErrCodeMissingArgument, message, fmt.Sprintf("%sThe following required arguments were not provided:%s\n%s\n\n%s\n\nRun %s--help%s for more information",
 		ColorRed+ColorBold, ColorReset,
 		argsText,
 		usage,
-		ColorGreen, ColorReset)
+		ColorGreen, ColorReset))
 }
 
 // SanitizeTemplateName ensures template name is safe
+// Names may contain one namespace segment (namespace/name) but no traversal
 func SanitizeTemplateName(name string) error {
 	if name == "" {
 		return fmt.Errorf("template name cannot be empty")
 	}
-	if strings.Contains(name, "/") || strings.Contains(name, "\\") {
+	if strings.Contains(name, "\\") {
 		return fmt.Errorf("template name cannot contain path separators")
 	}
-	if name == "." || name == ".." {
-		return fmt.Errorf("invalid template name")
+	if strings.Contains(name, "@") {
+		return fmt.Errorf("template name cannot contain '@' (reserved for name@version)")
+	}
+
+	// A single namespace segment is allowed (e.g. "platform/go-service")
+	segments := strings.Split(name, "/")
+	if len(segments) > 2 {
+		return fmt.Errorf("template name can only contain one namespace (namespace/name)")
+	}
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid template name")
+		}
+		if strings.HasPrefix(segment, ".") {
+			return fmt.Errorf("template name cannot start with '.'")
+		}
 	}
 	return nil
 }
@@ -3,82 +3,123 @@ package template
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/lancher-dev/lancher/internal/cli/shared"
+	"github.com/lancher-dev/lancher/internal/config"
 	"github.com/lancher-dev/lancher/internal/fileutil"
+	"github.com/lancher-dev/lancher/internal/gitutil"
+	"github.com/lancher-dev/lancher/internal/ignore"
+	"github.com/lancher-dev/lancher/internal/registry"
+	"github.com/lancher-dev/lancher/internal/sources"
 	"github.com/lancher-dev/lancher/internal/storage"
 )
 
-// isGitURL checks if source is a git URL
-func isGitURL(source string) bool {
-	return strings.HasPrefix(source, "http://") ||
-		strings.HasPrefix(source, "https://") ||
-		strings.HasPrefix(source, "git@") ||
-		strings.HasSuffix(source, ".git")
-}
-
-// isGitHubAlias checks if source uses gh: alias
-func isGitHubAlias(source string) bool {
-	return strings.HasPrefix(source, "gh:")
-}
-
-// isGitLabAlias checks if source uses gl: alias
-func isGitLabAlias(source string) bool {
-	return strings.HasPrefix(source, "gl:")
-}
-
-// ensureGitSuffix adds .git suffix if not present
-func ensureGitSuffix(url string) string {
-	if !strings.HasSuffix(url, ".git") {
-		return url + ".git"
-	}
-	return url
-}
-
-// isZipFile checks if source is a ZIP file
-func isZipFile(source string) bool {
-	return strings.HasSuffix(strings.ToLower(source), ".zip")
-}
-
 // RunAddHelp displays help for template add command
 func RunAddHelp() error {
-	fmt.Printf("%slancher template add%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("Add a new template\n\n")
-
-	fmt.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    lancher template add [name] [source] [options]\n\n")
-
-	fmt.Printf("%sARGS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    %s%-15s%s %s\n", shared.ColorGreen, "name", shared.ColorReset, "Template name (interactive if omitted)")
-	fmt.Printf("    %s%-15s%s %s\n\n", shared.ColorGreen, "source", shared.ColorReset, "Local path, ZIP file, git URL, or alias (gh:, gl:)")
-
-	fmt.Printf("%sALIASES:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    %sgh:%s<repo>     %sGitHub repository (uses GitHub CLI if available)%s\n", shared.ColorGreen, shared.ColorReset, "", "")
-	fmt.Printf("    %sgl:%s<repo>     %sGitLab repository (uses GitLab CLI if available)%s\n\n", shared.ColorGreen, shared.ColorReset, "", "")
-
-	fmt.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    %s-p%s, %s--print%s  %sShow detailed output (no spinner)%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
-	fmt.Printf("    %s-h%s, %s--help%s   %sShow this help message%s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("%slancher template add%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
+	shared.Printf("Add a new template\n\n")
+
+	shared.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    lancher template add [name] [source] [options]\n\n")
+
+	shared.Printf("%sARGS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s%-15s%s %s\n", shared.ColorGreen, "name", shared.ColorReset, "Template name or team/name (interactive if omitted)")
+	shared.Printf("    %s%-15s%s %s\n\n", shared.ColorGreen, "source", shared.ColorReset, "Local path, archive (zip, tar, tar.gz, tar.xz, tar.zst), archive URL, git URL, or alias (gh:, gl:)")
+
+	shared.Printf("%sALIASES:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %sgh:%s<repo>     %sGitHub repository (uses GitHub CLI if available)%s\n", shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %sgl:%s<repo>     %sGitLab repository (uses GitLab CLI if available)%s\n", shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    Custom aliases can be defined under %saliases%s in the user config\n", shared.ColorGreen, shared.ColorReset)
+	shared.Printf("    Append %s//<path>%s to a git source to add one directory (gh:org/templates//go)\n\n", shared.ColorGreen, shared.ColorReset)
+
+	shared.Printf("%sNAMESPACES:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    A name like %steam/api%s stores the template in the %steam%s namespace, created as needed.\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset)
+	shared.Printf("    In a project's %s.lancher/templates%s or the system templates directory, mark a\n", shared.ColorCyan, shared.ColorReset)
+	shared.Printf("    namespace with an empty %s%s%s file; otherwise its directory is a template.\n\n", shared.ColorGreen, storage.NamespaceMarker, shared.ColorReset)
+
+	shared.Printf("%sARCHIVE URLS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    An HTTP(S) link is downloaded as an archive when its path ends in an archive\n")
+	shared.Printf("    extension or when %s--sha256%s or %s--strip%s is given. Other links are cloned with git;\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset)
+	shared.Printf("    if that fails, they are downloaded when the server reports an archive Content-Type.\n\n")
+
+	shared.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s    --ref%s %s<ref>%s             %sPin a git template to a branch, tag or commit%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --subdir%s %s<path>%s        %sOnly add this directory of a git repository%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --sha256%s %s<hash>%s        %sVerify a downloaded archive URL against this checksum%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --strip%s                 %sExtract an archive without its single top-level directory (e.g. repo-main/)%s\n", shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --link%s                  %sLink a local directory instead of copying it; edits apply immediately%s\n", shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --symlinks%s %s<policy>%s     %sCopy symlinks of a local path: preserve (default), follow or skip%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --respect-gitignore%s     %sSkip files ignored by git (local paths only)%s\n", shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --no-respect-gitignore%s  %sCopy git-ignored files even if enabled in config%s\n", shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s-p%s, %s--print%s                 %sShow detailed output (no spinner)%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s-h%s, %s--help%s                  %sShow this help message%s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
 
 	return nil
 }
 
 // runAdd adds a new template from path or git repository
-func RunAdd(args []string) error {
-	var name, source string
-	var verbose bool
+func RunAdd(args []string) (err error) {
+	var name, source, ref, subdir, checksum, symlinks string
+	var verbose, respectGitignore, noRespectGitignore, strip, link bool
 
 	// Parse flags first
 	for i := 0; i < len(args); i++ {
-		if args[i] == "-p" || args[i] == "--print" {
+		n := 1
+		switch args[i] {
+		case "-p", "--print":
 			verbose = true
-			// Remove flag from args
-			args = append(args[:i], args[i+1:]...)
-			i--
+		case "--respect-gitignore":
+			respectGitignore = true
+		case "--no-respect-gitignore":
+			noRespectGitignore = true
+		case "--strip":
+			strip = true
+		case "--link":
+			link = true
+		case "--ref":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return shared.FormatError("--ref requires a branch, tag or commit")
+			}
+			ref = args[i+1]
+			n = 2
+		case "--subdir":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return shared.FormatError("--subdir requires a path")
+			}
+			subdir = args[i+1]
+			n = 2
+		case "--symlinks":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return shared.FormatError("--symlinks requires a policy (preserve, follow or skip)")
+			}
+			symlinks = args[i+1]
+			n = 2
+		case "--sha256":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return shared.FormatError("--sha256 requires a checksum")
+			}
+			checksum = args[i+1]
+			n = 2
+		default:
+			continue
 		}
+		// Remove flag (and its value) from args
+		args = append(args[:i], args[i+n:]...)
+		i--
+	}
+
+	if respectGitignore && noRespectGitignore {
+		return shared.FormatError("cannot use both --respect-gitignore and --no-respect-gitignore flags")
+	}
+
+	userCfg, err := config.LoadUserConfig()
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("Failed to load user config: %v", err))
+	}
+	if !noRespectGitignore && userCfg.Add.RespectGitignore {
+		respectGitignore = true
 	}
 
 	// Interactive mode if no arguments provided
@@ -86,48 +127,48 @@ func RunAdd(args []string) error {
 		nameInput, err := shared.PromptStringWithDefault("Enter template name:", "my-template")
 		if err != nil {
 			if strings.Contains(err.Error(), "cancelled") {
-				fmt.Printf("%sCancelled.%s\n", shared.ColorYellow, shared.ColorReset)
+				shared.Printf("%sCancelled.%s\n", shared.ColorYellow, shared.ColorReset)
 				return nil
 			}
 			return shared.FormatError("failed to read input")
 		}
 		name = nameInput
-		fmt.Printf("%s✓ Template name:%s %s\n", shared.ColorGreen, shared.ColorReset, name)
+		shared.Printf("%s✓ Template name:%s %s\n", shared.ColorGreen, shared.ColorReset, name)
 
 		if name == "" {
 			return shared.FormatError("Template name cannot be empty")
 		}
 
-		sourceInput, err := shared.PromptStringWithDefault("Enter source (local path, git URL, or ZIP file):", ".")
+		sourceInput, err := shared.PromptStringWithDefault("Enter source (local path, git URL, or archive):", ".")
 		if err != nil {
 			if strings.Contains(err.Error(), "cancelled") {
-				fmt.Printf("%sCancelled.%s\n", shared.ColorYellow, shared.ColorReset)
+				shared.Printf("%sCancelled.%s\n", shared.ColorYellow, shared.ColorReset)
 				return nil
 			}
 			return shared.FormatError("failed to read input")
 		}
-		source = sourceInput
+		source = userCfg.ExpandAlias(sourceInput)
 
 		if source == "" {
 			return shared.FormatError("Source cannot be empty")
 		}
 
 		// Validate source before printing confirmation
-		if !isGitURL(source) && !isGitHubAlias(source) && !isGitLabAlias(source) {
-			// For local paths and ZIP files, verify they exist
+		if !sources.IsRemote(source) && !sources.IsArchiveURL(source) {
+			// For local paths and archives, verify they exist
 			sourceAbs, err := filepath.Abs(source)
 			if err != nil {
 				return shared.FormatError(fmt.Sprintf("Invalid source path '%s'", source))
 			}
 			if _, err := os.Stat(sourceAbs); os.IsNotExist(err) {
-				if isZipFile(source) {
-					return shared.FormatError(fmt.Sprintf("ZIP file not found: '%s'", source))
+				if sources.IsArchive(source) {
+					return shared.FormatError(fmt.Sprintf("Archive not found: '%s'", source))
 				}
 				return shared.FormatError(fmt.Sprintf("Directory not found: '%s'", source))
 			}
 		}
 
-		fmt.Printf("%s✓ Source:%s %s\n", shared.ColorGreen, shared.ColorReset, source)
+		shared.Printf("%s✓ Source:%s %s\n", shared.ColorGreen, shared.ColorReset, source)
 	} else {
 		// Command-line arguments mode
 		if len(args) < 2 {
@@ -141,7 +182,7 @@ func RunAdd(args []string) error {
 			return shared.FormatMissingArgsError(missing, usage)
 		}
 		name = args[0]
-		source = args[1]
+		source = userCfg.ExpandAlias(args[1])
 	}
 
 	// Validate template name
@@ -149,6 +190,65 @@ func RunAdd(args []string) error {
 		return shared.FormatError(err.Error())
 	}
 
+	// Links without an archive extension are archives when given --sha256 or --strip;
+	// other ones are cloned first and only probed as archives if that fails
+	archiveURL := sources.DetectArchiveURL(source, checksum) || (strip && sources.IsHTTPURL(source))
+
+	// A repository subdirectory can be given as "<repo>//<subdir>" or with --subdir
+	if repo, dir := sources.SplitSubdir(source); dir != "" && !archiveURL && sources.IsRemote(repo) {
+		if subdir != "" {
+			return shared.FormatError("cannot use both <repo>//<subdir> and --subdir")
+		}
+		source, subdir = repo, dir
+	}
+	if subdir != "" {
+		if !sources.IsRemote(source) || archiveURL {
+			return shared.FormatError("--subdir can only be used with git sources")
+		}
+		if subdir, err = sources.CleanSubdir(subdir); err != nil {
+			return shared.FormatError(err.Error())
+		}
+	}
+	if ref != "" && (!sources.IsRemote(source) || archiveURL) {
+		return shared.FormatError("--ref can only be used with git sources")
+	}
+	if checksum != "" {
+		if !archiveURL {
+			return shared.FormatError("--sha256 can only be used with archive URLs")
+		}
+		if checksum, err = sources.NormalizeSHA256(checksum); err != nil {
+			return shared.FormatError(err.Error())
+		}
+	}
+
+	if strip && !sources.IsArchive(source) && !archiveURL {
+		return shared.FormatError("--strip can only be used with archives")
+	}
+
+	links, err := fileutil.ParseSymlinkPolicy(symlinks)
+	if err != nil {
+		return shared.FormatError(err.Error())
+	}
+	if symlinks != "" && (sources.IsRemote(source) || archiveURL || sources.IsArchive(source)) {
+		return shared.FormatError("--symlinks can only be used with local directories")
+	}
+
+	var linkTarget string
+	if link {
+		if sources.IsRemote(source) || archiveURL {
+			return shared.FormatError("--link can only be used with local directories")
+		}
+		if symlinks != "" {
+			return shared.FormatError("cannot use --symlinks together with --link")
+		}
+		if linkTarget, err = filepath.Abs(source); err != nil {
+			return shared.FormatError(fmt.Sprintf("Invalid source path: %v", err))
+		}
+		if info, err := os.Stat(linkTarget); err != nil || !info.IsDir() {
+			return shared.FormatError(fmt.Sprintf("Directory not found: '%s'", linkTarget))
+		}
+	}
+
 	// Check if template already exists
 	exists, err := storage.TemplateExists(name)
 	if err != nil {
@@ -158,26 +258,67 @@ func RunAdd(args []string) error {
 		return shared.FormatError(fmt.Sprintf("Template '%s' already exists", name))
 	}
 
+	// Create the namespace directory for namespaced names
+	if err := storage.CreateNamespace(name); err != nil {
+		return shared.FormatError(fmt.Sprintf("Cannot add template '%s': %v", name, err))
+	}
+	// A failed add leaves nothing behind: no partial copy and no namespace created for it
+	defer func() {
+		if err == nil {
+			return
+		}
+		if destPath, pathErr := storage.GetTemplatePath(name); pathErr == nil {
+			os.RemoveAll(destPath)
+		}
+		storage.RemoveEmptyNamespace(name)
+	}()
+
+	// Handle a directory linked in place
+	if link {
+		return addLink(name, linkTarget)
+	}
+
 	// Get destination path
 	destPath, err := storage.GetTemplatePath(name)
 	if err != nil {
 		return shared.FormatError(fmt.Sprintf("Failed to get template path: %v", err))
 	}
 
+	// Handle a single directory of a repository
+	if subdir != "" {
+		return addSubdir(name, source, subdir, destPath, ref, verbose)
+	}
+
+	// Handle an archive downloaded over HTTP(S)
+	if archiveURL {
+		return addArchiveURL(name, source, destPath, checksum, strip, verbose)
+	}
+
 	// Handle GitHub alias (gh:)
-	if isGitHubAlias(source) {
-		repoPath := strings.TrimPrefix(source, "gh:")
-		return cloneWithAlias(name, repoPath, destPath, "gh", "https://github.com/", verbose)
+	if sources.IsGitHubAlias(source) {
+		if err := cloneWithAlias(name, source, destPath, ref, verbose); err != nil {
+			return err
+		}
+		if err := saveMetadata(name, destPath, &registry.Entry{SourceType: registry.SourceGitHub, Source: source, Ref: ref}); err != nil {
+			warnMetadata(err)
+		}
+		return nil
 	}
 
 	// Handle GitLab alias (gl:)
-	if isGitLabAlias(source) {
-		repoPath := strings.TrimPrefix(source, "gl:")
-		return cloneWithAlias(name, repoPath, destPath, "glab", "https://gitlab.com/", verbose)
+	if sources.IsGitLabAlias(source) {
+		if err := cloneWithAlias(name, source, destPath, ref, verbose); err != nil {
+			return err
+		}
+		if err := saveMetadata(name, destPath, &registry.Entry{SourceType: registry.SourceGitLab, Source: source, Ref: ref}); err != nil {
+			warnMetadata(err)
+		}
+		return nil
 	}
 
-	// Handle git URL, ZIP file, or local path
-	if isGitURL(source) {
+	// Handle git URL, archive, or local path
+	var entry *registry.Entry
+	if sources.IsGitURL(source) {
 		var spinner *shared.Spinner
 		writer := shared.NewSpinnerWriter(verbose)
 
@@ -186,13 +327,27 @@ func RunAdd(args []string) error {
 			spinner.Start()
 			defer spinner.Stop()
 		} else {
-			fmt.Printf("%sCloning repository...%s\n", shared.ColorYellow, shared.ColorReset)
+			shared.Printf("%sCloning repository...%s\n", shared.ColorYellow, shared.ColorReset)
 		}
 
-		cmd := exec.Command("git", "clone", "--depth", "1", source, destPath)
-		cmd.Stdout = writer.MultiWriter()
-		cmd.Stderr = writer.MultiWriter()
-		if err := cmd.Run(); err != nil {
+		err := gitutil.Clone(source, destPath, ref, writer.MultiWriter())
+		if err == nil {
+			// Servers answering every path, such as artifact links with a query, clone as empty repositories
+			if _, headErr := gitutil.HeadCommit(destPath); headErr != nil {
+				err = fmt.Errorf("repository has no commits")
+			}
+		}
+		if err != nil {
+			// A link that is not a repository may still serve an archive
+			if ref == "" && sources.ProbeArchiveURL(source) {
+				if spinner != nil {
+					spinner.Stop()
+				}
+				if err := os.RemoveAll(destPath); err != nil {
+					return shared.FormatError(fmt.Sprintf("Failed to clean up after clone: %v", err))
+				}
+				return addArchiveURL(name, source, destPath, "", strip, verbose)
+			}
 			if spinner != nil {
 				spinner.Fail(fmt.Sprintf("Failed to clone repository: %v", err))
 			}
@@ -202,42 +357,47 @@ func RunAdd(args []string) error {
 		if spinner != nil {
 			spinner.Success(fmt.Sprintf("Template '%s' added from git repository", name))
 		} else {
-			fmt.Printf("%s✓ Template '%s' added from git repository%s\n", shared.ColorGreen, name, shared.ColorReset)
+			shared.Printf("%s✓ Template '%s' added from git repository%s\n", shared.ColorGreen, name, shared.ColorReset)
+		}
+		shared.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, source)
+		if ref != "" {
+			shared.Printf("  %sRef:%s %s\n", shared.ColorYellow, shared.ColorReset, ref)
 		}
-		fmt.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, source)
-	} else if isZipFile(source) {
-		// ZIP file
+		entry = &registry.Entry{SourceType: registry.SourceGit, Source: source, Ref: ref}
+	} else if sources.IsArchive(source) {
+		// Archive file, format detected from its contents
 		sourceAbs, err := filepath.Abs(source)
 		if err != nil {
 			return shared.FormatError(fmt.Sprintf("Invalid source path: %v", err))
 		}
 
 		if _, err := os.Stat(sourceAbs); os.IsNotExist(err) {
-			return shared.FormatError(fmt.Sprintf("ZIP file not found: '%s'", sourceAbs))
+			return shared.FormatError(fmt.Sprintf("Archive not found: '%s'", sourceAbs))
 		}
 
 		var spinner *shared.Spinner
 		if !verbose {
-			spinner = shared.NewSpinner("Extracting ZIP file...")
+			spinner = shared.NewSpinner("Extracting archive...")
 			spinner.Start()
 			defer spinner.Stop()
 		} else {
-			fmt.Printf("%sExtracting ZIP file...%s\n", shared.ColorYellow, shared.ColorReset)
+			shared.Printf("%sExtracting archive...%s\n", shared.ColorYellow, shared.ColorReset)
 		}
 
-		if err := fileutil.UnzipToDir(sourceAbs, destPath); err != nil {
+		entry = &registry.Entry{SourceType: registry.SourceArchive, Source: sourceAbs, StripTopLevel: strip}
+		if err := fileutil.ExtractArchive(sourceAbs, destPath, entry.ExtractOptions()); err != nil {
 			if spinner != nil {
-				spinner.Fail(fmt.Sprintf("Failed to extract ZIP: %v", err))
+				spinner.Fail(fmt.Sprintf("Failed to extract archive: %v", err))
 			}
-			return shared.FormatError(fmt.Sprintf("Failed to extract ZIP: %v", err))
+			return shared.FormatError(fmt.Sprintf("Failed to extract archive: %v", err))
 		}
 
 		if spinner != nil {
-			spinner.Success(fmt.Sprintf("Template '%s' added from ZIP file", name))
+			spinner.Success(fmt.Sprintf("Template '%s' added from archive", name))
 		} else {
-			fmt.Printf("%s✓ Template '%s' added from ZIP file%s\n", shared.ColorGreen, name, shared.ColorReset)
+			shared.Printf("%s✓ Template '%s' added from archive%s\n", shared.ColorGreen, name, shared.ColorReset)
 		}
-		fmt.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, sourceAbs)
+		shared.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, sourceAbs)
 	} else {
 		// Local path
 		sourceAbs, err := filepath.Abs(source)
@@ -250,62 +410,166 @@ func RunAdd(args []string) error {
 		}
 
 		// Copy directory
-		if err := fileutil.CopyDir(sourceAbs, destPath); err != nil {
+		if err := fileutil.CopyTreeWithLinks(sourceAbs, destPath, addIgnoreOptions(respectGitignore), links); err != nil {
 			return shared.FormatError(fmt.Sprintf("Failed to copy template: %v", err))
 		}
 
-		fmt.Printf("%s✓ Template '%s' added successfully%s\n", shared.ColorGreen, name, shared.ColorReset)
-		fmt.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, sourceAbs)
+		shared.Printf("%s✓ Template '%s' added successfully%s\n", shared.ColorGreen, name, shared.ColorReset)
+		shared.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, sourceAbs)
+		entry = &registry.Entry{SourceType: registry.SourceLocal, Source: sourceAbs, RespectGitignore: respectGitignore, Symlinks: symlinks}
 	}
 
-	fmt.Printf("  %sStored:%s %s\n", shared.ColorYellow, shared.ColorReset, destPath)
+	shared.Printf("  %sStored:%s %s\n", shared.ColorYellow, shared.ColorReset, destPath)
+
+	if err := saveMetadata(name, destPath, entry); err != nil {
+		warnMetadata(err)
+	}
 
 	return nil
 }
 
-// cloneWithAlias handles cloning with gh: or gl: alias
-// cliCmd is "gh" for GitHub or "glab" for GitLab
-// baseURL is the base HTTPS URL for the platform
-func cloneWithAlias(name, repoPath, destPath, cliCmd, baseURL string, verbose bool) error {
+// addIgnoreOptions returns the rules applied when copying a local directory into the store
+// The .git directory of a working tree is never copied; the template is its files, not its history
+func addIgnoreOptions(respectGitignore bool) ignore.Options {
+	return ignore.Options{
+		Exclude:   []string{".git"},
+		Files:     []string{ignore.LancherIgnoreFile},
+		GitIgnore: respectGitignore,
+	}
+}
+
+// addSubdir adds one directory of a git repository as a template
+// The stored copy is not a git clone; updates re-clone the repository and copy the directory again
+func addSubdir(name, source, subdir, destPath, ref string, verbose bool) error {
 	var spinner *shared.Spinner
 	writer := shared.NewSpinnerWriter(verbose)
 
-	var cmd *exec.Cmd
-	var sourceDisplay string
+	if !verbose {
+		spinner = shared.NewSpinner("Cloning repository...")
+		spinner.Start()
+		defer spinner.Stop()
+	} else {
+		shared.Printf("%sCloning repository...%s\n", shared.ColorYellow, shared.ColorReset)
+	}
 
-	if shared.CommandExists(cliCmd) {
-		// Use CLI tool (gh repo clone or glab repo clone)
-		if !verbose {
-			spinner = shared.NewSpinner(fmt.Sprintf("Cloning with CLI..."))
-			spinner.Start()
-			defer spinner.Stop()
-		} else {
-			fmt.Printf("%sCloning with CLI...%s\n", shared.ColorYellow, shared.ColorReset)
+	commit, err := sources.CloneSubdir(source, subdir, destPath, ref, writer.MultiWriter())
+	if err != nil {
+		os.RemoveAll(destPath)
+		if spinner != nil {
+			spinner.Fail(fmt.Sprintf("Failed to add repository subdirectory: %v", err))
 		}
+		return shared.FormatError(fmt.Sprintf("Failed to add repository subdirectory: %v", err))
+	}
 
-		cmd = exec.Command(cliCmd, "repo", "clone", repoPath, destPath, "--", "--depth", "1")
-		sourceDisplay = fmt.Sprintf("%s:%s", cliCmd, repoPath)
+	if spinner != nil {
+		spinner.Success(fmt.Sprintf("Template '%s' added from repository subdirectory", name))
 	} else {
-		// Fallback to git clone with HTTPS URL
-		gitURL := baseURL + repoPath
-		gitURL = ensureGitSuffix(gitURL)
+		shared.Printf("%s✓ Template '%s' added from repository subdirectory%s\n", shared.ColorGreen, name, shared.ColorReset)
+	}
+	shared.Printf("  %sSource:%s %s//%s\n", shared.ColorYellow, shared.ColorReset, source, subdir)
+	if ref != "" {
+		shared.Printf("  %sRef:%s %s\n", shared.ColorYellow, shared.ColorReset, ref)
+	}
+	shared.Printf("  %sStored:%s %s\n", shared.ColorYellow, shared.ColorReset, destPath)
 
-		if !verbose {
-			spinner = shared.NewSpinner("Cloning repository...")
-			spinner.Start()
-			defer spinner.Stop()
-		} else {
-			fmt.Printf("%sCloning repository...%s\n", shared.ColorYellow, shared.ColorReset)
+	entry := &registry.Entry{SourceType: gitSourceType(source), Source: source, Ref: ref, Subdir: subdir, Commit: commit}
+	if err := saveMetadata(name, destPath, entry); err != nil {
+		warnMetadata(err)
+	}
+	return nil
+}
+
+// addLink registers a local directory by reference instead of copying it
+func addLink(name, target string) error {
+	linkPath, err := storage.CreateLink(name, target)
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("Failed to link template: %v", err))
+	}
+
+	shared.Printf("%s✓ Template '%s' linked%s\n", shared.ColorGreen, name, shared.ColorReset)
+	shared.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, target)
+	shared.Printf("  %sStored:%s %s -> %s\n", shared.ColorYellow, shared.ColorReset, linkPath, target)
+
+	if err := saveMetadata(name, linkPath, &registry.Entry{SourceType: registry.SourceLink, Source: target}); err != nil {
+		warnMetadata(err)
+	}
+	return nil
+}
+
+// addArchiveURL downloads an archive, verifies it against checksum if set and extracts it as a template
+// The URL and checksum are recorded so updates download and verify it again
+func addArchiveURL(name, url, destPath, checksum string, strip, verbose bool) error {
+	var spinner *shared.Spinner
+	if !verbose {
+		spinner = shared.NewSpinner("Downloading archive...")
+		spinner.Start()
+		defer spinner.Stop()
+	} else {
+		shared.Printf("%sDownloading archive...%s\n", shared.ColorYellow, shared.ColorReset)
+	}
+
+	entry := &registry.Entry{SourceType: registry.SourceArchive, Source: url, SHA256: checksum, StripTopLevel: strip}
+	sum, err := sources.FetchArchive(url, destPath, checksum, entry.ExtractOptions())
+	if err != nil {
+		os.RemoveAll(destPath)
+		if spinner != nil {
+			spinner.Fail(fmt.Sprintf("Failed to add archive: %v", err))
 		}
+		return shared.FormatError(fmt.Sprintf("Failed to add archive: %v", err))
+	}
 
-		cmd = exec.Command("git", "clone", "--depth", "1", gitURL, destPath)
-		sourceDisplay = gitURL
+	if spinner != nil {
+		spinner.Success(fmt.Sprintf("Template '%s' added from archive URL", name))
+	} else {
+		shared.Printf("%s✓ Template '%s' added from archive URL%s\n", shared.ColorGreen, name, shared.ColorReset)
+	}
+	shared.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, url)
+	shared.Printf("  %sSHA-256:%s %s", shared.ColorYellow, shared.ColorReset, sum)
+	if checksum == "" {
+		shared.Printf(" %s(not verified, pin it with --sha256)%s", shared.ColorGray, shared.ColorReset)
 	}
+	shared.Println()
+	shared.Printf("  %sStored:%s %s\n", shared.ColorYellow, shared.ColorReset, destPath)
 
-	cmd.Stdout = writer.MultiWriter()
-	cmd.Stderr = writer.MultiWriter()
+	if err := saveMetadata(name, destPath, entry); err != nil {
+		warnMetadata(err)
+	}
+	return nil
+}
+
+// gitSourceType returns the registry source type of a git URL or alias
+func gitSourceType(source string) string {
+	switch {
+	case sources.IsGitHubAlias(source):
+		return registry.SourceGitHub
+	case sources.IsGitLabAlias(source):
+		return registry.SourceGitLab
+	default:
+		return registry.SourceGit
+	}
+}
+
+// cloneWithAlias handles cloning with gh: or gl: alias
+// The GitHub or GitLab CLI is used if available, otherwise git clone over HTTPS
+// ref optionally pins the clone to a branch, tag or commit
+func cloneWithAlias(name, alias, destPath, ref string, verbose bool) error {
+	var spinner *shared.Spinner
+	writer := shared.NewSpinnerWriter(verbose)
+
+	message := "Cloning repository..."
+	if sources.UsesCLI(alias) {
+		message = "Cloning with CLI..."
+	}
+	if !verbose {
+		spinner = shared.NewSpinner(message)
+		spinner.Start()
+		defer spinner.Stop()
+	} else {
+		shared.Printf("%s%s%s\n", shared.ColorYellow, message, shared.ColorReset)
+	}
 
-	if err := cmd.Run(); err != nil {
+	sourceDisplay, err := sources.Clone(alias, destPath, ref, writer.MultiWriter())
+	if err != nil {
 		if spinner != nil {
 			spinner.Fail(fmt.Sprintf("Failed to clone repository: %v", err))
 		}
@@ -315,10 +579,13 @@ func cloneWithAlias(name, repoPath, destPath, cliCmd, baseURL string, verbose bo
 	if spinner != nil {
 		spinner.Success(fmt.Sprintf("Template '%s' added from repository", name))
 	} else {
-		fmt.Printf("%s✓ Template '%s' added from repository%s\n", shared.ColorGreen, name, shared.ColorReset)
+		shared.Printf("%s✓ Template '%s' added from repository%s\n", shared.ColorGreen, name, shared.ColorReset)
+	}
+	shared.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, sourceDisplay)
+	if ref != "" {
+		shared.Printf("  %sRef:%s %s\n", shared.ColorYellow, shared.ColorReset, ref)
 	}
-	fmt.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, sourceDisplay)
-	fmt.Printf("  %sStored:%s %s\n", shared.ColorYellow, shared.ColorReset, destPath)
+	shared.Printf("  %sStored:%s %s\n", shared.ColorYellow, shared.ColorReset, destPath)
 
 	return nil
 }
@@ -0,0 +1,378 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lancher-dev/lancher/internal/cli/shared"
+	"github.com/lancher-dev/lancher/internal/config"
+	"github.com/lancher-dev/lancher/internal/fileutil"
+	"github.com/lancher-dev/lancher/internal/gitutil"
+	"github.com/lancher-dev/lancher/internal/ignore"
+	"github.com/lancher-dev/lancher/internal/registry"
+	"github.com/lancher-dev/lancher/internal/storage"
+)
+
+// maxTreeEntries bounds the file tree printed by template info
+const maxTreeEntries = 200
+
+// readmeNames lists the README files shown by template info, in order of priority
+var readmeNames = []string{"README.md", "README.markdown", "README", "README.txt"}
+
+// RunInfoHelp displays help for template info command
+func RunInfoHelp() error {
+	shared.Printf("%slancher template info%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
+	shared.Printf("Show the details of a template\n\n")
+
+	shared.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    lancher template info <name> [options]\n\n")
+
+	shared.Printf("%sDESCRIPTION:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    Prints the metadata, config file, hooks, ignore rules and source of a\n")
+	shared.Printf("    template, the files a create would emit and its README.\n\n")
+
+	shared.Printf("%sARGS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s%-15s%s %s\n\n", shared.ColorGreen, "name", shared.ColorReset, "Template name")
+
+	shared.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s--no-readme%s      %sDo not print the README%s\n", shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s-h%s, %s--help%s       %sShow this help message%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+
+	return nil
+}
+
+// templateInfo holds everything template info reports about a template
+type templateInfo struct {
+	template    storage.Template
+	linkTarget  string
+	loadResult  *config.LoadResult
+	entry       *registry.Entry
+	commit      string
+	size        int64
+	fileCount   int
+	files       []string // Files a create would emit
+	ignoreFiles []string // .lancherignore files in the template
+	readme      string   // README file name, if any
+}
+
+// RunInfo prints the details of a template
+func RunInfo(args []string) error {
+	usage := "USAGE:\n    lancher template info <name> [options]"
+	var templateName string
+	showReadme := true
+
+	for _, arg := range args {
+		switch {
+		case arg == "--no-readme":
+			showReadme = false
+		case strings.HasPrefix(arg, "-"):
+			return shared.FormatUnknownCommandError(arg, usage, "lancher template info ")
+		case templateName == "":
+			templateName = arg
+		default:
+			return shared.FormatError(fmt.Sprintf("unexpected argument '%s'", arg))
+		}
+	}
+	if templateName == "" {
+		return shared.FormatMissingArgsError([]string{"name"}, usage)
+	}
+
+	// Validate template name
+	if err := shared.SanitizeTemplateName(templateName); err != nil {
+		return shared.FormatError(err.Error())
+	}
+
+	tmpl, found, err := storage.FindTemplate(templateName)
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to check template: %v", err))
+	}
+	if !found {
+		return shared.FormatNotFoundError(templateName)
+	}
+
+	info, err := loadTemplateInfo(tmpl)
+	if err != nil {
+		return shared.FormatError(err.Error())
+	}
+
+	if shared.JSONOutput() {
+		return printTemplateInfoJSON(info, showReadme)
+	}
+
+	printTemplateInfo(info)
+	if showReadme && info.readme != "" {
+		printReadme(filepath.Join(tmpl.Path, info.readme))
+	}
+
+	return nil
+}
+
+// loadTemplateInfo collects the details of a template
+func loadTemplateInfo(tmpl storage.Template) (*templateInfo, error) {
+	info := &templateInfo{template: tmpl}
+
+	if target, linked := storage.LinkTarget(tmpl.Path); linked {
+		info.linkTarget = target
+		if _, err := os.Stat(tmpl.Path); err != nil {
+			return nil, fmt.Errorf("template '%s' is linked to %s, which no longer exists", tmpl.Name, target)
+		}
+	}
+
+	info.loadResult = config.LoadConfigWithDetails(tmpl.Path)
+
+	// The record is missing for templates copied into the store by hand
+	if entry, err := registry.Load(tmpl.Root.Path, tmpl.Name); err == nil {
+		info.entry = entry
+	}
+	if gitutil.IsRepository(tmpl.Path) {
+		if head, err := gitutil.HeadCommit(tmpl.Path); err == nil {
+			info.commit = head
+		}
+	}
+	if info.commit == "" && info.entry != nil {
+		info.commit = info.entry.Commit
+	}
+
+	size, count, err := fileutil.DirSize(tmpl.Path)
+	if err != nil {
+		return nil, err
+	}
+	info.size, info.fileCount = size, count
+
+	info.files, err = listTemplateFiles(tmpl.Path, fileutil.SymlinkPreserve)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	stored, err := ignore.List(tmpl.Path, ignore.Options{Exclude: []string{".git"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	for _, file := range stored {
+		if filepath.Base(file) == ignore.LancherIgnoreFile {
+			info.ignoreFiles = append(info.ignoreFiles, filepath.ToSlash(file))
+		}
+	}
+
+	info.readme = findReadme(tmpl.Path)
+	return info, nil
+}
+
+// findReadme returns the name of the README at the root of a template, or ""
+func findReadme(templatePath string) string {
+	entries, err := os.ReadDir(templatePath)
+	if err != nil {
+		return ""
+	}
+	for _, name := range readmeNames {
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.EqualFold(entry.Name(), name) {
+				return entry.Name()
+			}
+		}
+	}
+	return ""
+}
+
+// printTemplateInfo prints every section of template info except the README
+func printTemplateInfo(info *templateInfo) {
+	tmpl := info.template
+	cfg := info.loadResult.Config
+
+	shared.Printf("%s%s%s %s(%s)%s\n", shared.ColorGreen+shared.ColorBold, tmpl.Name, shared.ColorReset, shared.ColorGray, tmpl.Root.Scope, shared.ColorReset)
+	if info.linkTarget != "" {
+		printField("Path", fmt.Sprintf("%s -> %s", tmpl.Path, info.linkTarget))
+	} else {
+		printField("Path", tmpl.Path)
+	}
+	if info.entry != nil {
+		printField("Source", formatSource(info.entry))
+	}
+	if info.commit != "" {
+		printField("Commit", info.commit)
+	}
+	if info.entry != nil && info.entry.SHA256 != "" {
+		printField("SHA-256", info.entry.SHA256)
+	}
+	printField("Size", fmt.Sprintf("%s in %d files", shared.FormatSize(info.size), info.fileCount))
+	if info.entry != nil && !info.entry.UpdatedAt.IsZero() {
+		printField("Updated", info.entry.UpdatedAt.Local().Format("2006-01-02 15:04"))
+	}
+
+	// Metadata from the config file
+	printSection("Metadata")
+	if cfg == nil {
+		shared.Printf("  %sNo config file%s\n", shared.ColorGray, shared.ColorReset)
+	} else {
+		printField("Name", cfg.Name)
+		printField("Description", cfg.Description)
+		printField("Author", cfg.Author)
+		printField("Version", cfg.Version)
+		printField("Category", cfg.Category)
+		printField("Tags", strings.Join(cfg.Tags, ", "))
+	}
+
+	// Resolved config file and the ones it shadows
+	if len(info.loadResult.FoundFiles) > 0 {
+		printSection("Config")
+		if info.loadResult.UsedFile != "" {
+			shared.Printf("  %s✓%s %s\n", shared.ColorGreen, shared.ColorReset, info.loadResult.UsedFile)
+		}
+		// Files are found in order of priority, so any found before the used one failed to load
+		used := false
+		for _, file := range info.loadResult.FoundFiles {
+			if file == info.loadResult.UsedFile {
+				used = true
+				continue
+			}
+			if !used {
+				shared.Printf("  %s⚠ %s (invalid, ignored)%s\n", shared.ColorYellow, file, shared.ColorReset)
+			} else {
+				shared.Printf("  %s⚠ %s (shadowed by %s)%s\n", shared.ColorYellow, file, info.loadResult.UsedFile, shared.ColorReset)
+			}
+		}
+	}
+
+	if cfg.HasHooks() {
+		printSection("Hooks")
+		for i, hook := range cfg.Hooks {
+			shared.Printf("  %s%d.%s %s\n", shared.ColorGray, i+1, shared.ColorReset, hook)
+		}
+
+		// Variables from the user config are passed to hooks in the environment
+		if userCfg, err := config.LoadUserConfig(); err == nil {
+			if env := userCfg.HookEnv(); len(env) > 0 {
+				printSection("Variables")
+				for _, variable := range env {
+					shared.Printf("  %s\n", variable)
+				}
+			}
+		}
+	}
+
+	printSection("Ignore")
+	shared.Printf("  %sAlways:%s .git, %s\n", shared.ColorGray, shared.ColorReset, strings.Join(config.ConfigFileNames, ", "))
+	if cfg != nil && len(cfg.Ignore) > 0 {
+		shared.Printf("  %sConfig:%s %s\n", shared.ColorGray, shared.ColorReset, strings.Join(cfg.Ignore, ", "))
+	}
+	if len(info.ignoreFiles) > 0 {
+		shared.Printf("  %sFiles:%s  %s\n", shared.ColorGray, shared.ColorReset, strings.Join(info.ignoreFiles, ", "))
+	}
+
+	// Files a create would emit
+	printSection(fmt.Sprintf("Files (%d)", len(info.files)))
+	if len(info.files) == 0 {
+		shared.Printf("  %sNo files%s\n", shared.ColorGray, shared.ColorReset)
+	}
+	paths := make([]string, len(info.files))
+	for i, file := range info.files {
+		paths[i] = filepath.ToSlash(file)
+	}
+	tree := shared.FormatTree(paths)
+	for i, line := range tree {
+		if i == maxTreeEntries {
+			shared.Printf("  %s… %d more (see lancher template ls-files %s)%s\n", shared.ColorGray, len(tree)-i, tmpl.Name, shared.ColorReset)
+			break
+		}
+		shared.Printf("  %s\n", line)
+	}
+}
+
+// templateInfoJSON describes a template in the JSON output of template info
+type templateInfoJSON struct {
+	templateJSON
+	Commit    string      `json:"commit,omitempty"`
+	Size      int64       `json:"size"`
+	FileCount int         `json:"file_count"`
+	Files     []string    `json:"files"` // Files a create would emit
+	Ignore    ignoreJSON  `json:"ignore"`
+	HookEnv   []string    `json:"hook_env,omitempty"`
+	Readme    *readmeJSON `json:"readme,omitempty"`
+}
+
+// ignoreJSON describes the ignore rules of a template
+type ignoreJSON struct {
+	Always   []string `json:"always"`
+	Patterns []string `json:"patterns"`
+	Files    []string `json:"files"`
+}
+
+// readmeJSON is the README of a template
+type readmeJSON struct {
+	File    string `json:"file"`
+	Content string `json:"content,omitempty"`
+}
+
+// printTemplateInfoJSON prints the details of a template as JSON
+func printTemplateInfoJSON(info *templateInfo, showReadme bool) error {
+	cfg := info.loadResult.Config
+	out := templateInfoJSON{
+		templateJSON: newTemplateJSON(info.template, info.loadResult),
+		Commit:       info.commit,
+		Size:         info.size,
+		FileCount:    info.fileCount,
+		Files:        make([]string, len(info.files)),
+		Ignore: ignoreJSON{
+			Always:   append([]string{".git"}, config.ConfigFileNames...),
+			Patterns: []string{},
+			Files:    []string{},
+		},
+	}
+	for i, file := range info.files {
+		out.Files[i] = filepath.ToSlash(file)
+	}
+	if cfg != nil && len(cfg.Ignore) > 0 {
+		out.Ignore.Patterns = cfg.Ignore
+	}
+	if len(info.ignoreFiles) > 0 {
+		out.Ignore.Files = info.ignoreFiles
+	}
+	if cfg.HasHooks() {
+		if userCfg, err := config.LoadUserConfig(); err == nil {
+			out.HookEnv = userCfg.HookEnv()
+		}
+	}
+	if info.readme != "" {
+		out.Readme = &readmeJSON{File: info.readme}
+		if showReadme {
+			data, err := os.ReadFile(filepath.Join(info.template.Path, info.readme))
+			if err != nil {
+				return shared.FormatError(fmt.Sprintf("failed to read %s: %v", info.readme, err))
+			}
+			out.Readme.Content = string(data)
+		}
+	}
+
+	return shared.PrintJSON(out)
+}
+
+// printReadme prints a README, rendering it if it is markdown
+func printReadme(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		shared.Printf("\n%s⚠ Failed to read %s: %v%s\n", shared.ColorYellow, filepath.Base(path), err, shared.ColorReset)
+		return
+	}
+
+	printSection(filepath.Base(path))
+	text := strings.TrimSpace(string(data))
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".md" || ext == ".markdown" {
+		text = shared.RenderMarkdown(text)
+	}
+	shared.Println(text)
+}
+
+// printSection prints a section header of template info
+func printSection(title string) {
+	shared.Printf("\n%s%s:%s\n", shared.ColorCyan+shared.ColorBold, title, shared.ColorReset)
+}
+
+// printField prints a labelled value, skipping empty values
+func printField(label, value string) {
+	if value == "" {
+		return
+	}
+	shared.Printf("  %s%s:%s %s\n", shared.ColorGray, label, shared.ColorReset, value)
+}
@@ -0,0 +1,49 @@
+package template
+
+import (
+	"os"
+
+	"github.com/lancher-dev/lancher/internal/config"
+	"github.com/lancher-dev/lancher/internal/registry"
+	"github.com/lancher-dev/lancher/internal/storage"
+)
+
+// templateJSON describes a template in JSON output
+type templateJSON struct {
+	Name        string          `json:"name"`
+	Scope       storage.Scope   `json:"scope"`
+	Path        string          `json:"path"`
+	Linked      bool            `json:"linked"`
+	LinkTarget  string          `json:"link_target,omitempty"`
+	Missing     bool            `json:"missing,omitempty"` // Linked directory no longer exists
+	Shadowed    bool            `json:"shadowed"`
+	ShadowedBy  string          `json:"shadowed_by,omitempty"`
+	Config      *config.Config  `json:"config"`
+	ConfigFile  string          `json:"config_file,omitempty"`
+	ConfigFiles []string        `json:"config_files"`
+	Source      *registry.Entry `json:"source"`
+}
+
+// newTemplateJSON describes a template with its loaded configuration
+func newTemplateJSON(tmpl storage.Template, loadResult *config.LoadResult) templateJSON {
+	out := templateJSON{
+		Name:        tmpl.Name,
+		Scope:       tmpl.Root.Scope,
+		Path:        tmpl.Path,
+		Linked:      tmpl.Linked,
+		Shadowed:    tmpl.Shadowed,
+		Config:      loadResult.Config,
+		ConfigFile:  loadResult.UsedFile,
+		ConfigFiles: loadResult.FoundFiles,
+	}
+	if target, linked := storage.LinkTarget(tmpl.Path); linked {
+		out.LinkTarget = target
+		if _, err := os.Stat(tmpl.Path); err != nil {
+			out.Missing = true
+		}
+	}
+	if entry, err := registry.Load(tmpl.Root.Path, tmpl.Name); err == nil {
+		out.Source = entry
+	}
+	return out
+}
@@ -2,79 +2,243 @@ package template
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 
 	"github.com/lancher-dev/lancher/internal/cli/shared"
 	"github.com/lancher-dev/lancher/internal/config"
+	"github.com/lancher-dev/lancher/internal/registry"
 	"github.com/lancher-dev/lancher/internal/storage"
 )
 
 // RunListHelp displays help for template list command
 func RunListHelp() error {
-	fmt.Printf("%slancher template list%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("List all available templates\n\n")
+	shared.Printf("%slancher template list%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
+	shared.Printf("List all available templates\n\n")
 
-	fmt.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    lancher template list\n")
-	fmt.Printf("    lancher template ls\n")
-	fmt.Printf("    lancher templates\n\n")
+	shared.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    lancher template list\n")
+	shared.Printf("    lancher template ls\n")
+	shared.Printf("    lancher templates\n\n")
 
-	fmt.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    %s-h%s, %s--help%s  %sShow this help message%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("%sSEARCH PATHS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s%-10s%s %s\n", shared.ColorGreen, storage.ScopeProject, shared.ColorReset, storage.ProjectTemplatesDir+" in the current or a parent directory")
+	shared.Printf("    %s%-10s%s %s\n", shared.ColorGreen, storage.ScopeUser, shared.ColorReset, "User templates directory")
+	shared.Printf("    %s%-10s%s %s\n", shared.ColorGreen, storage.ScopeSystem, shared.ColorReset, storage.SystemTemplatesDir)
+	shared.Printf("    Templates in earlier paths shadow templates with the same name in later ones\n\n")
+
+	shared.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s--tag%s %s<tag>%s            %sOnly show templates with this tag (repeatable)%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "", "")
+	shared.Printf("    %s--category%s %s<name>%s      %sOnly show templates in this category%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "", "")
+	shared.Printf("    %s--namespace%s %s<name>%s     %sOnly show templates in this namespace%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "", "")
+	shared.Printf("    %s-h%s, %s--help%s              %sShow this help message%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
 
 	return nil
 }
 
+// listEntry is a template with its loaded configuration
+type listEntry struct {
+	template   storage.Template
+	loadResult *config.LoadResult
+}
+
 // runList lists all available templates
 func RunList(args []string) error {
-	templates, err := storage.ListTemplates()
+	var tags []string
+	var category, namespace string
+
+	// Parse flags
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--tag":
+			if i+1 < len(args) {
+				tags = append(tags, args[i+1])
+				i++
+			} else {
+				return shared.FormatError("flag --tag requires a value")
+			}
+		case "--category":
+			if i+1 < len(args) {
+				category = args[i+1]
+				i++
+			} else {
+				return shared.FormatError("flag --category requires a value")
+			}
+		case "--namespace":
+			if i+1 < len(args) {
+				namespace = args[i+1]
+				i++
+			} else {
+				return shared.FormatError("flag --namespace requires a value")
+			}
+		default:
+			usage := "USAGE:\n    lancher template list [OPTIONS]"
+			return shared.FormatUnknownCommandError(args[i], usage, "lancher template list ")
+		}
+	}
+
+	templates, err := storage.ListAllTemplates()
 	if err != nil {
 		return shared.FormatError(fmt.Sprintf("failed to list templates: %v", err))
 	}
 
-	if len(templates) == 0 {
-		fmt.Printf("%sNo templates found.%s\n", shared.ColorYellow, shared.ColorReset)
-		fmt.Printf("Add a template with: %slancher template add <name> <source_dir>%s\n", shared.ColorCyan, shared.ColorReset)
+	if len(templates) == 0 && !shared.JSONOutput() {
+		shared.Printf("%sNo templates found.%s\n", shared.ColorYellow, shared.ColorReset)
+		shared.Printf("Add a template with: %slancher template add <name> <source_dir>%s\n", shared.ColorCyan, shared.ColorReset)
 		return nil
 	}
 
-	fmt.Printf("%sAvailable Templates:%s\n\n", shared.ColorBold, shared.ColorReset)
-	for i, name := range templates {
-		templatePath, _ := storage.GetTemplatePath(name)
+	// Remember which root provides each name, to report shadowing
+	active := make(map[string]storage.Template)
+	for _, tmpl := range templates {
+		if !tmpl.Shadowed {
+			active[tmpl.Name] = tmpl
+		}
+	}
+
+	// Load configs and apply filters
+	var entries []listEntry
+	for _, tmpl := range templates {
+		if ns, _ := storage.SplitName(tmpl.Name); namespace != "" && ns != namespace {
+			continue
+		}
+		loadResult := config.LoadConfigWithDetails(tmpl.Path)
+		if !loadResult.Config.MatchesFilter(tags, category) {
+			continue
+		}
+		entries = append(entries, listEntry{template: tmpl, loadResult: loadResult})
+	}
+
+	if shared.JSONOutput() {
+		out := make([]templateJSON, 0, len(entries))
+		for _, entry := range entries {
+			tmpl := newTemplateJSON(entry.template, entry.loadResult)
+			if entry.template.Shadowed {
+				tmpl.ShadowedBy = active[entry.template.Name].Path
+			}
+			out = append(out, tmpl)
+		}
+		return shared.PrintJSON(map[string]any{"templates": out})
+	}
+
+	if len(entries) == 0 {
+		shared.Printf("%sNo templates match the given filters.%s\n", shared.ColorYellow, shared.ColorReset)
+		return nil
+	}
 
-		// Load .lancher.yaml config with details
-		loadResult := config.LoadConfigWithDetails(templatePath)
+	// Group by category: named categories alphabetically, uncategorized last
+	sort.SliceStable(entries, func(i, j int) bool {
+		ci := entries[i].loadResult.Config.GetCategory()
+		cj := entries[j].loadResult.Config.GetCategory()
+		if (ci == "") != (cj == "") {
+			return cj == ""
+		}
+		return strings.ToLower(ci) < strings.ToLower(cj)
+	})
+	grouped := entries[0].loadResult.Config.GetCategory() != ""
+
+	shared.Printf("%sAvailable Templates:%s\n\n", shared.ColorBold, shared.ColorReset)
+	for i, entry := range entries {
+		tmpl := entry.template
+		name := tmpl.Name
+		templatePath := tmpl.Path
+		loadResult := entry.loadResult
 		cfg := loadResult.Config
 
-		fmt.Printf("  %s•%s %s%s%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorBold, name, shared.ColorReset)
-		fmt.Printf("    %sPath:%s %s\n", shared.ColorGray, shared.ColorReset, templatePath)
+		// Print category header when the category changes
+		if grouped {
+			current := cfg.GetCategory()
+			if i == 0 || !strings.EqualFold(entries[i-1].loadResult.Config.GetCategory(), current) {
+				if current == "" {
+					current = "Uncategorized"
+				}
+				shared.Printf("%s%s%s\n", shared.ColorCyan+shared.ColorBold, current, shared.ColorReset)
+			}
+		}
+
+		// Namespaces are shown as a dimmed prefix
+		displayName := name
+		if ns, base := storage.SplitName(name); ns != "" {
+			displayName = fmt.Sprintf("%s%s/%s%s", shared.ColorGray, ns, shared.ColorReset+shared.ColorBold, base)
+		}
+
+		scope := string(tmpl.Root.Scope)
+		if tmpl.Linked {
+			scope += ", linked"
+		}
+		if tmpl.Shadowed {
+			shared.Printf("  %s•%s %s%s%s %s(%s, shadowed)%s\n", shared.ColorGray, shared.ColorReset, shared.ColorGray, name, shared.ColorReset, shared.ColorGray, scope, shared.ColorReset)
+		} else {
+			shared.Printf("  %s•%s %s%s%s %s(%s)%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorBold, displayName, shared.ColorReset, shared.ColorGray, scope, shared.ColorReset)
+		}
+		if target, linked := storage.LinkTarget(templatePath); linked {
+			shared.Printf("    %sPath:%s %s -> %s\n", shared.ColorGray, shared.ColorReset, templatePath, target)
+		} else {
+			shared.Printf("    %sPath:%s %s\n", shared.ColorGray, shared.ColorReset, templatePath)
+		}
+
+		// Display where the template came from, if recorded
+		if entry, err := registry.Load(tmpl.Root.Path, name); err == nil && entry != nil {
+			shared.Printf("    %sSource:%s %s\n", shared.ColorGray, shared.ColorReset, formatSource(entry))
+		}
 
 		// Display metadata from .lancher.yaml if available
 		if cfg != nil {
 			if cfg.Name != "" && cfg.Name != name {
-				fmt.Printf("    %sName:%s %s\n", shared.ColorGray, shared.ColorReset, cfg.Name)
+				shared.Printf("    %sName:%s %s\n", shared.ColorGray, shared.ColorReset, cfg.Name)
 			}
 			if cfg.Description != "" {
-				fmt.Printf("    %sDescription:%s %s\n", shared.ColorGray, shared.ColorReset, cfg.Description)
+				shared.Printf("    %sDescription:%s %s\n", shared.ColorGray, shared.ColorReset, cfg.Description)
 			}
 			if cfg.Author != "" {
-				fmt.Printf("    %sAuthor:%s %s\n", shared.ColorGray, shared.ColorReset, cfg.Author)
+				shared.Printf("    %sAuthor:%s %s\n", shared.ColorGray, shared.ColorReset, cfg.Author)
 			}
 			if cfg.Version != "" {
-				fmt.Printf("    %sVersion:%s %s\n", shared.ColorGray, shared.ColorReset, cfg.Version)
+				shared.Printf("    %sVersion:%s %s\n", shared.ColorGray, shared.ColorReset, cfg.Version)
+			}
+			if len(cfg.Tags) > 0 {
+				shared.Printf("    %sTags:%s %s\n", shared.ColorGray, shared.ColorReset, strings.Join(cfg.Tags, ", "))
 			}
 		}
 
 		// Show warning if multiple config files found
 		if len(loadResult.FoundFiles) > 1 {
-			fmt.Printf("    %s⚠ Warning: Multiple config files found (%v). Using %s%s\n",
+			shared.Printf("    %s⚠ Warning: Multiple config files found (%v). Using %s%s\n",
 				shared.ColorYellow, loadResult.FoundFiles, loadResult.UsedFile, shared.ColorReset)
 		}
 
+		// A linked template whose directory was moved or deleted cannot be used
+		if tmpl.Linked {
+			if _, err := os.Stat(templatePath); err != nil {
+				shared.Printf("    %s⚠ Linked directory is missing; remove the template or restore the directory%s\n",
+					shared.ColorYellow, shared.ColorReset)
+			}
+		}
+
+		// Show which template takes precedence over a shadowed one
+		if tmpl.Shadowed {
+			shared.Printf("    %s⚠ Shadowed by the %s template at %s%s\n",
+				shared.ColorYellow, active[name].Root.Scope, active[name].Path, shared.ColorReset)
+		}
+
 		// Add extra spacing between templates
-		if i < len(templates)-1 {
-			fmt.Println()
+		if i < len(entries)-1 {
+			shared.Println()
 		}
 	}
 
 	return nil
 }
+
+// formatSource returns a one-line description of a template source
+func formatSource(entry *registry.Entry) string {
+	source := fmt.Sprintf("%s (%s)", entry.Location(), entry.SourceType)
+	if entry.Ref != "" {
+		source += " @ " + entry.Ref
+	}
+	if commit := entry.ShortCommit(); commit != "" {
+		source += " " + commit
+	}
+	return source
+}
@@ -0,0 +1,93 @@
+package template
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/lancher-dev/lancher/internal/cli/shared"
+	"github.com/lancher-dev/lancher/internal/config"
+	"github.com/lancher-dev/lancher/internal/fileutil"
+	"github.com/lancher-dev/lancher/internal/storage"
+)
+
+// RunLsFilesHelp displays help for template ls-files command
+func RunLsFilesHelp() error {
+	shared.Printf("%slancher template ls-files%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
+	shared.Printf("List the files a create would emit from a template\n\n")
+
+	shared.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    lancher template ls-files <name> [options]\n\n")
+
+	shared.Printf("%sARGS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s%-15s%s %s\n\n", shared.ColorGreen, "name", shared.ColorReset, "Template name")
+
+	shared.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s    --symlinks%s %s<policy>%s  %sList symlinks as create copies them: preserve (default), follow or skip%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "", "")
+	shared.Printf("    %s-h%s, %s--help%s              %sShow this help message%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+
+	return nil
+}
+
+// RunLsFiles prints the files a create would emit, one per line
+func RunLsFiles(args []string) error {
+	usage := "USAGE:\n    lancher template ls-files <name> [options]"
+	var templateName, symlinks string
+
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--symlinks":
+			if i+1 >= len(args) {
+				return shared.FormatError("flag --symlinks requires a value")
+			}
+			symlinks = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "-"):
+			return shared.FormatUnknownCommandError(args[i], usage, "lancher template ls-files ")
+		case templateName == "":
+			templateName = args[i]
+		}
+	}
+	if templateName == "" {
+		return shared.FormatMissingArgsError([]string{"name"}, usage)
+	}
+
+	links, err := fileutil.ParseSymlinkPolicy(symlinks)
+	if err != nil {
+		return shared.FormatError(err.Error())
+	}
+
+	// Validate template name
+	if err := shared.SanitizeTemplateName(templateName); err != nil {
+		return shared.FormatError(err.Error())
+	}
+
+	tmpl, found, err := storage.FindTemplate(templateName)
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to check template: %v", err))
+	}
+	if !found {
+		return shared.FormatNotFoundError(templateName)
+	}
+
+	files, err := listTemplateFiles(tmpl.Path, links)
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to list files: %v", err))
+	}
+
+	for _, file := range files {
+		shared.Println(filepath.ToSlash(file))
+	}
+
+	return nil
+}
+
+// listTemplateFiles returns the files create would copy from a template
+// The listing comes from the walk create copies with, so followed symlinks list their contents
+func listTemplateFiles(templatePath string, links fileutil.SymlinkPolicy) ([]string, error) {
+	cfg, err := config.LoadConfig(templatePath)
+	if err != nil {
+		return nil, err
+	}
+	return fileutil.ListTree(templatePath, cfg.IgnoreOptions(), links)
+}
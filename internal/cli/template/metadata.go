@@ -0,0 +1,100 @@
+package template
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lancher-dev/lancher/internal/cli/shared"
+	"github.com/lancher-dev/lancher/internal/fileutil"
+	"github.com/lancher-dev/lancher/internal/gitutil"
+	"github.com/lancher-dev/lancher/internal/registry"
+	"github.com/lancher-dev/lancher/internal/storage"
+	"github.com/lancher-dev/lancher/internal/versions"
+)
+
+// saveMetadata records the metadata of a template in the user store
+// Commit, ref and content hash are read from the stored copy;
+// an existing record keeps its original AddedAt timestamp
+func saveMetadata(name, templatePath string, entry *registry.Entry) error {
+	root, err := storage.GetTemplatesDir()
+	if err != nil {
+		return err
+	}
+
+	existing, err := registry.Load(root, name)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	entry.Name = name
+	entry.AddedAt = now
+	if existing != nil && !existing.AddedAt.IsZero() {
+		entry.AddedAt = existing.AddedAt
+	}
+	entry.UpdatedAt = now
+
+	// Only clones are read with git; a .git directory copied from a local checkout or an
+	// archive says nothing about the recorded source
+	if entry.IsGit() && gitutil.IsRepository(templatePath) {
+		if entry.Commit, err = gitutil.HeadCommit(templatePath); err != nil {
+			return err
+		}
+		if entry.Ref == "" {
+			if entry.Ref, err = gitutil.CurrentBranch(templatePath); err != nil {
+				return err
+			}
+		}
+	}
+
+	// A linked directory changes freely, so its content is not tracked
+	if entry.SourceType != registry.SourceLink {
+		if entry.Hash, err = fileutil.HashDir(templatePath); err != nil {
+			return err
+		}
+	}
+
+	return registry.Save(root, entry)
+}
+
+// loadMetadata reads the metadata record of a template in the user store
+func loadMetadata(name string) (*registry.Entry, error) {
+	root, err := storage.GetTemplatesDir()
+	if err != nil {
+		return nil, err
+	}
+	return registry.Load(root, name)
+}
+
+// removeMetadata deletes the metadata record and stored versions of a template in the user store
+func removeMetadata(name string) error {
+	root, err := storage.GetTemplatesDir()
+	if err != nil {
+		return err
+	}
+	if err := registry.Remove(root, name); err != nil {
+		return err
+	}
+	return versions.Remove(root, name)
+}
+
+// warnMetadata reports a metadata failure without failing the command
+func warnMetadata(err error) {
+	shared.Printf("%s⚠ Failed to record template metadata: %v%s\n", shared.ColorYellow, err, shared.ColorReset)
+}
+
+// checkUserTemplate returns an error unless name is a template of the user store
+// Project and system templates are reported as read-only rather than not found
+func checkUserTemplate(name string) error {
+	exists, err := storage.TemplateExists(name)
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to check template '%s': %v", name, err))
+	}
+	if exists {
+		return nil
+	}
+	if tmpl, found, err := storage.FindTemplate(name); err == nil && found {
+		return shared.FormatReadOnlyError(name, string(tmpl.Root.Scope), tmpl.Root.Path)
+	}
+	return shared.FormatNotFoundError(name)
+}
@@ -11,18 +11,18 @@ import (
 
 // RunRemoveHelp displays help for template remove command
 func RunRemoveHelp() error {
-	fmt.Printf("%slancher template remove%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("Remove one or more templates\n\n")
+	shared.Printf("%slancher template remove%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
+	shared.Printf("Remove one or more templates\n\n")
 
-	fmt.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    lancher template remove [name...]\n")
-	fmt.Printf("    lancher template rm [name...]\n\n")
+	shared.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    lancher template remove [name...]\n")
+	shared.Printf("    lancher template rm [name...]\n\n")
 
-	fmt.Printf("%sARGS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    %s%-15s%s %s\n\n", shared.ColorGreen, "name", shared.ColorReset, "Template name(s) (interactive multi-select if omitted)")
+	shared.Printf("%sARGS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s%-15s%s %s\n\n", shared.ColorGreen, "name", shared.ColorReset, "Template name(s) (interactive multi-select if omitted)")
 
-	fmt.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    %s-h%s, %s--help%s  %sShow this help message%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s-h%s, %s--help%s  %sShow this help message%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
 
 	return nil
 }
@@ -33,27 +33,28 @@ func RunRemove(args []string) error {
 
 	// If no args, show interactive multi-selection
 	if len(args) == 0 {
-		templates, err := storage.ListTemplates()
+		// Project and system templates cannot be removed, so only the user store is offered
+		templates, err := storage.ListUserTemplates()
 		if err != nil {
 			return shared.FormatError(fmt.Sprintf("failed to list templates: %v", err))
 		}
 
 		if len(templates) == 0 {
-			fmt.Printf("%sNo templates found.%s\n", shared.ColorYellow, shared.ColorReset)
+			shared.Printf("%sNo templates found.%s\n", shared.ColorYellow, shared.ColorReset)
 			return nil
 		}
 
 		selected, err := shared.MultiSelect("Select templates to remove:", templates)
 		if err != nil {
 			if strings.Contains(err.Error(), "cancelled") {
-				fmt.Printf("%sCancelled.%s\n", shared.ColorYellow, shared.ColorReset)
+				shared.Printf("%sCancelled.%s\n", shared.ColorYellow, shared.ColorReset)
 				return nil
 			}
 			return shared.FormatError(fmt.Sprintf("selection failed: %v", err))
 		}
 
 		if len(selected) == 0 {
-			fmt.Printf("%sNo templates selected.%s\n", shared.ColorYellow, shared.ColorReset)
+			shared.Printf("%sNo templates selected.%s\n", shared.ColorYellow, shared.ColorReset)
 			return nil
 		}
 
@@ -69,13 +70,9 @@ func RunRemove(args []string) error {
 			return shared.FormatError(fmt.Sprintf("invalid template name '%s': %s", name, err.Error()))
 		}
 
-		// Check if template exists
-		exists, err := storage.TemplateExists(name)
-		if err != nil {
-			return shared.FormatError(fmt.Sprintf("failed to check template '%s': %v", name, err))
-		}
-		if !exists {
-			return shared.FormatError(fmt.Sprintf("template '%s' not found", name))
+		// Check if template exists in the user store
+		if err := checkUserTemplate(name); err != nil {
+			return err
 		}
 	}
 
@@ -93,7 +90,8 @@ func RunRemove(args []string) error {
 			continue
 		}
 
-		// Remove directory
+		// Remove directory; for a linked template only the link is removed
+		_, linked := storage.LinkTarget(templatePath)
 		if err := fileutil.RemoveDir(templatePath); err != nil {
 			if firstError == nil {
 				firstError = fmt.Errorf("failed to remove template '%s': %w", name, err)
@@ -101,7 +99,18 @@ func RunRemove(args []string) error {
 			continue
 		}
 
-		fmt.Printf("%s✓ Template '%s' removed successfully%s\n", shared.ColorGreen, name, shared.ColorReset)
+		if err := removeMetadata(name); err != nil && firstError == nil {
+			firstError = fmt.Errorf("failed to remove metadata for '%s': %w", name, err)
+		}
+		if err := storage.RemoveEmptyNamespace(name); err != nil && firstError == nil {
+			firstError = fmt.Errorf("failed to remove namespace of '%s': %w", name, err)
+		}
+
+		if linked {
+			shared.Printf("%s✓ Template '%s' unlinked (the linked directory is kept)%s\n", shared.ColorGreen, name, shared.ColorReset)
+		} else {
+			shared.Printf("%s✓ Template '%s' removed successfully%s\n", shared.ColorGreen, name, shared.ColorReset)
+		}
 		removedCount++
 	}
 
@@ -110,7 +119,7 @@ func RunRemove(args []string) error {
 	}
 
 	if removedCount > 1 {
-		fmt.Printf("\n%s✓ Successfully removed %d templates%s\n", shared.ColorGreen, removedCount, shared.ColorReset)
+		shared.Printf("\n%s✓ Successfully removed %d templates%s\n", shared.ColorGreen, removedCount, shared.ColorReset)
 	}
 
 	return nil
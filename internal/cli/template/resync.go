@@ -0,0 +1,207 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lancher-dev/lancher/internal/cli/shared"
+	"github.com/lancher-dev/lancher/internal/fileutil"
+	"github.com/lancher-dev/lancher/internal/gitutil"
+	"github.com/lancher-dev/lancher/internal/registry"
+	"github.com/lancher-dev/lancher/internal/sources"
+)
+
+// maxDiffLines is the number of paths listed per change kind unless verbose
+const maxDiffLines = 10
+
+// resyncTemplate re-copies a template from its recorded local directory, archive, archive URL or
+// repository subdirectory
+// ref moves the pin of a repository subdirectory; checksum moves the pin of an archive URL
+func resyncTemplate(name, templatePath, ref, checksum string, verbose bool) error {
+	entry, err := loadMetadata(name)
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to read template metadata: %v", err))
+	}
+	if entry == nil {
+		return shared.FormatError(fmt.Sprintf("template '%s' is not a git repository and has no recorded source\nUse -d <path> to overwrite with new files", name))
+	}
+
+	if ref != "" && (!entry.IsGit() || entry.Subdir == "") {
+		return shared.FormatError(fmt.Sprintf("template '%s' is not a git repository, --ref cannot be used", name))
+	}
+	if checksum != "" && (!entry.IsArchive() || !sources.IsHTTPURL(entry.Source)) {
+		return shared.FormatError(fmt.Sprintf("template '%s' was not added from an archive URL, --sha256 cannot be used", name))
+	}
+
+	var populate func(staging string) error
+	var message string
+
+	switch {
+	case entry.IsGit() && entry.Subdir != "":
+		if ref != "" {
+			entry.Ref = ref
+		}
+		writer := shared.NewSpinnerWriter(verbose)
+		message = "Cloning repository..."
+		populate = func(staging string) error {
+			commit, err := sources.CloneSubdir(entry.Source, entry.Subdir, staging, entry.Ref, writer.MultiWriter())
+			entry.Commit = commit
+			return err
+		}
+	case entry.SourceType == registry.SourceLocal:
+		if info, err := os.Stat(entry.Source); err != nil || !info.IsDir() {
+			return shared.FormatError(fmt.Sprintf("source directory no longer exists: %s\nUse -d <path> to overwrite with new files", entry.Source))
+		}
+		links, err := fileutil.ParseSymlinkPolicy(entry.Symlinks)
+		if err != nil {
+			return shared.FormatError(err.Error())
+		}
+		message = "Copying from source directory..."
+		populate = func(staging string) error {
+			return fileutil.CopyTreeWithLinks(entry.Source, staging, addIgnoreOptions(entry.RespectGitignore), links)
+		}
+	case entry.IsArchive() && sources.IsHTTPURL(entry.Source):
+		if checksum != "" {
+			entry.SHA256 = checksum
+		}
+		message = "Downloading archive..."
+		populate = func(staging string) error {
+			_, err := sources.FetchArchive(entry.Source, staging, entry.SHA256, entry.ExtractOptions())
+			return err
+		}
+	case entry.IsArchive():
+		if _, err := os.Stat(entry.Source); err != nil {
+			return shared.FormatError(fmt.Sprintf("archive no longer exists: %s\nUse -d <path> to overwrite with new files", entry.Source))
+		}
+		message = "Extracting archive..."
+		populate = func(staging string) error {
+			return fileutil.ExtractArchive(entry.Source, staging, entry.ExtractOptions())
+		}
+	default:
+		return shared.FormatError(fmt.Sprintf("template '%s' is not a git repository (recorded source: %s)\nUse -d <path> to overwrite with new files", name, entry.Source))
+	}
+
+	var spinner *shared.Spinner
+	if !verbose {
+		spinner = shared.NewSpinner(message)
+		spinner.Start()
+		defer spinner.Stop()
+	} else {
+		shared.Printf("%s%s%s\n", shared.ColorYellow, message, shared.ColorReset)
+	}
+
+	diff, err := replaceTemplate(templatePath, populate)
+	if err != nil {
+		if spinner != nil {
+			spinner.Fail(fmt.Sprintf("Failed to update template: %v", err))
+		}
+		return shared.FormatError(fmt.Sprintf("failed to update template: %v", err))
+	}
+
+	if diff.Empty() {
+		if spinner != nil {
+			spinner.Success(fmt.Sprintf("Template '%s' is already up to date", name))
+		} else {
+			shared.Printf("%s✓ Template '%s' is already up to date%s\n", shared.ColorGreen, name, shared.ColorReset)
+		}
+		shared.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, entry.Location())
+
+		// The files are unchanged, but the pinned ref, commit or checksum may have moved
+		if entry.IsGit() || checksum != "" {
+			if err := saveMetadata(name, templatePath, entry); err != nil {
+				warnMetadata(err)
+			}
+		}
+		return nil
+	}
+
+	if spinner != nil {
+		spinner.Success(fmt.Sprintf("Template '%s' re-synced from %s", name, entry.SourceType))
+	} else {
+		shared.Printf("%s✓ Template '%s' re-synced from %s%s\n", shared.ColorGreen, name, entry.SourceType, shared.ColorReset)
+	}
+	shared.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, entry.Location())
+	shared.Printf("  %sStored:%s %s\n", shared.ColorYellow, shared.ColorReset, templatePath)
+	printTreeDiff(diff, verbose)
+
+	if err := saveMetadata(name, templatePath, entry); err != nil {
+		warnMetadata(err)
+	}
+
+	return nil
+}
+
+// replaceTemplate builds a new copy of a template in a staging directory and swaps it in
+// The existing template is left untouched if populating fails or nothing changed; a
+// git template whose files are unchanged is still swapped when its HEAD commit moved
+func replaceTemplate(templatePath string, populate func(staging string) error) (*fileutil.TreeDiff, error) {
+	info, err := os.Stat(templatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stage next to the template so the final rename stays on the same filesystem
+	staging, err := os.MkdirTemp(filepath.Dir(templatePath), ".staging-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := os.Chmod(staging, info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+	if err := populate(staging); err != nil {
+		return nil, err
+	}
+
+	diff, err := fileutil.DiffTrees(templatePath, staging)
+	if err != nil {
+		return nil, err
+	}
+	if diff.Empty() && headCommit(templatePath) == headCommit(staging) {
+		return diff, nil
+	}
+
+	backup := staging + ".old"
+	if err := os.Rename(templatePath, backup); err != nil {
+		return nil, fmt.Errorf("failed to move old template: %w", err)
+	}
+	if err := os.Rename(staging, templatePath); err != nil {
+		os.Rename(backup, templatePath)
+		return nil, fmt.Errorf("failed to move new template into place: %w", err)
+	}
+	os.RemoveAll(backup)
+
+	return diff, nil
+}
+
+// headCommit returns the commit checked out in dir, or "" if dir is not a git repository
+// File diffs and hashes leave .git out, so a moved ref is only seen through its commit
+func headCommit(dir string) string {
+	if !gitutil.IsRepository(dir) {
+		return ""
+	}
+	commit, _ := gitutil.HeadCommit(dir)
+	return commit
+}
+
+// printTreeDiff prints a summary of added, changed and removed files
+// Long lists are truncated unless verbose
+func printTreeDiff(diff *fileutil.TreeDiff, verbose bool) {
+	shared.Printf("  %sChanges:%s %d added, %d changed, %d removed\n", shared.ColorYellow, shared.ColorReset,
+		len(diff.Added), len(diff.Changed), len(diff.Removed))
+
+	printPaths := func(paths []string, marker, color string) {
+		for i, path := range paths {
+			if !verbose && i == maxDiffLines {
+				shared.Printf("    %s… and %d more%s\n", shared.ColorGray, len(paths)-maxDiffLines, shared.ColorReset)
+				return
+			}
+			shared.Printf("    %s%s%s %s\n", color, marker, shared.ColorReset, path)
+		}
+	}
+	printPaths(diff.Added, "+", shared.ColorGreen)
+	printPaths(diff.Changed, "~", shared.ColorYellow)
+	printPaths(diff.Removed, "-", shared.ColorRed)
+}
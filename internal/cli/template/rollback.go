@@ -0,0 +1,139 @@
+package template
+
+import (
+	"fmt"
+
+	"github.com/lancher-dev/lancher/internal/cli/shared"
+	"github.com/lancher-dev/lancher/internal/fileutil"
+	"github.com/lancher-dev/lancher/internal/ignore"
+	"github.com/lancher-dev/lancher/internal/registry"
+	"github.com/lancher-dev/lancher/internal/storage"
+	"github.com/lancher-dev/lancher/internal/versions"
+)
+
+// RunRollbackHelp displays help for template rollback command
+func RunRollbackHelp() error {
+	shared.Printf("%slancher template rollback%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
+	shared.Printf("Restore a template from a snapshot taken before an update\n\n")
+
+	shared.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    lancher template rollback <name> [options]\n\n")
+
+	shared.Printf("%sDESCRIPTION:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    Without --to, the most recent snapshot is restored. The restored snapshot\n")
+	shared.Printf("    is removed, so repeated rollbacks step further back.\n\n")
+
+	shared.Printf("%sARGS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s%-15s%s %s\n\n", shared.ColorGreen, "name", shared.ColorReset, "Template name to roll back")
+
+	shared.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s    --to%s %s<snapshot>%s  %sSnapshot ID or version to restore%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s-p%s, %s--print%s          %sShow every changed file%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s-h%s, %s--help%s           %sShow this help message%s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+
+	return nil
+}
+
+// RunRollback restores a template and its metadata from a snapshot
+func RunRollback(args []string) error {
+	var templateName, target string
+	var verbose bool
+
+	// Parse args and flags
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--to" && i+1 < len(args) {
+			target = args[i+1]
+			i++
+		} else if args[i] == "-p" || args[i] == "--print" {
+			verbose = true
+		} else if templateName == "" {
+			templateName = args[i]
+		}
+	}
+
+	if templateName == "" {
+		usage := "USAGE:\n    lancher template rollback <name> [OPTIONS]"
+		return shared.FormatMissingArgsError([]string{"name"}, usage)
+	}
+
+	// Validate template name
+	if err := shared.SanitizeTemplateName(templateName); err != nil {
+		return shared.FormatError(err.Error())
+	}
+
+	if err := checkUserTemplate(templateName); err != nil {
+		return err
+	}
+
+	templatePath, err := storage.GetTemplatePath(templateName)
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to get template path: %v", err))
+	}
+	root, err := storage.GetTemplatesDir()
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to get templates directory: %v", err))
+	}
+
+	snapshot, err := findSnapshot(root, templateName, target)
+	if err != nil {
+		return err
+	}
+
+	diff, err := replaceTemplate(templatePath, func(staging string) error {
+		return fileutil.CopyTree(snapshot.Path, staging, ignore.Options{})
+	})
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to restore snapshot: %v", err))
+	}
+
+	// Restore the metadata recorded with the snapshot
+	if snapshot.Entry != nil {
+		err = registry.Save(root, snapshot.Entry)
+	} else {
+		err = registry.Remove(root, templateName)
+	}
+	if err != nil {
+		warnMetadata(err)
+	}
+
+	if err := versions.Discard(snapshot); err != nil {
+		shared.Printf("%s⚠ Failed to remove restored snapshot: %v%s\n", shared.ColorYellow, err, shared.ColorReset)
+	}
+
+	shared.Printf("%s✓ Template '%s' rolled back to snapshot %s%s\n", shared.ColorGreen, templateName, snapshot.ID, shared.ColorReset)
+	if snapshot.Label != "" {
+		shared.Printf("  %sVersion:%s %s\n", shared.ColorYellow, shared.ColorReset, snapshot.Label)
+	}
+	if snapshot.Commit != "" {
+		shared.Printf("  %sCommit:%s %s\n", shared.ColorYellow, shared.ColorReset, snapshot.ShortCommit())
+	}
+	shared.Printf("  %sStored:%s %s\n", shared.ColorYellow, shared.ColorReset, templatePath)
+	printTreeDiff(diff, verbose)
+
+	return nil
+}
+
+// findSnapshot returns the snapshot matching target, or the most recent one if target is empty
+func findSnapshot(root, name, target string) (*versions.Version, error) {
+	if target != "" {
+		v, err := versions.Find(root, name, target)
+		if err != nil {
+			return nil, shared.FormatError(fmt.Sprintf("failed to read snapshots: %v", err))
+		}
+		if v == nil || v.Kind != versions.KindSnapshot {
+			return nil, shared.FormatError(fmt.Sprintf("snapshot '%s' of template '%s' not found\nRun 'lancher template versions %s' to list snapshots", target, name, name))
+		}
+		return v, nil
+	}
+
+	list, err := versions.List(root, name)
+	if err != nil {
+		return nil, shared.FormatError(fmt.Sprintf("failed to read snapshots: %v", err))
+	}
+	for i := range list {
+		if list[i].Kind == versions.KindSnapshot {
+			return &list[i], nil
+		}
+	}
+	return nil, shared.FormatError(fmt.Sprintf("template '%s' has no snapshots to roll back to", name))
+}
@@ -0,0 +1,191 @@
+package template
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lancher-dev/lancher/internal/cli/shared"
+	"github.com/lancher-dev/lancher/internal/config"
+	"github.com/lancher-dev/lancher/internal/fuzzy"
+	"github.com/lancher-dev/lancher/internal/storage"
+)
+
+// RunSearchHelp displays help for template search command
+func RunSearchHelp() error {
+	shared.Printf("%slancher template search%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
+	shared.Printf("Find templates by fuzzy matching their name, description, tags and author\n\n")
+
+	shared.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    lancher template search <query> [options]\n\n")
+
+	shared.Printf("%sDESCRIPTION:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    Every word of the query must match one of the fields, with its letters\n")
+	shared.Printf("    in order. Name matches rank highest, then tags, then description and author.\n\n")
+
+	shared.Printf("%sARGS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s%-15s%s %s\n\n", shared.ColorGreen, "query", shared.ColorReset, "Search query (multiple words allowed)")
+
+	shared.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s--limit%s %s<n>%s      %sShow at most n results%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorCyan, shared.ColorReset, "", "")
+	shared.Printf("    %s-h%s, %s--help%s       %sShow this help message%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+
+	return nil
+}
+
+// searchField is a template field matched by template search
+type searchField struct {
+	label  string
+	text   string
+	weight int
+}
+
+// searchResult is a template matching a search query
+type searchResult struct {
+	template  storage.Template
+	config    *config.Config
+	score     int
+	matched   []string // Labels of the fields that matched
+	positions []int    // Matched runes of the template name
+}
+
+// RunSearch lists the templates matching a query, best match first
+func RunSearch(args []string) error {
+	usage := "USAGE:\n    lancher template search <query> [options]"
+	var words []string
+	limit := 0
+
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--limit":
+			if i+1 >= len(args) {
+				return shared.FormatError("flag --limit requires a value")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 {
+				return shared.FormatError(fmt.Sprintf("invalid limit '%s'", args[i+1]))
+			}
+			limit = n
+			i++
+		case strings.HasPrefix(args[i], "-"):
+			return shared.FormatUnknownCommandError(args[i], usage, "lancher template search ")
+		default:
+			words = append(words, args[i])
+		}
+	}
+	query := strings.Join(words, " ")
+	if strings.TrimSpace(query) == "" {
+		return shared.FormatMissingArgsError([]string{"query"}, usage)
+	}
+
+	templates, err := storage.ListAllTemplates()
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to list templates: %v", err))
+	}
+
+	results := searchTemplates(templates, query)
+	if len(results) == 0 {
+		shared.Printf("%sNo templates match '%s'.%s\n", shared.ColorYellow, query, shared.ColorReset)
+		return nil
+	}
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	for i, result := range results {
+		tmpl := result.template
+		name := shared.HighlightMatches(tmpl.Name, result.positions, shared.ColorYellow+shared.ColorBold)
+		shared.Printf("  %s•%s %s%s%s %s(%s)%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorBold, name, shared.ColorReset, shared.ColorGray, tmpl.Root.Scope, shared.ColorReset)
+
+		if cfg := result.config; cfg != nil {
+			if cfg.Description != "" {
+				shared.Printf("    %sDescription:%s %s\n", shared.ColorGray, shared.ColorReset, cfg.Description)
+			}
+			if len(cfg.Tags) > 0 {
+				shared.Printf("    %sTags:%s %s\n", shared.ColorGray, shared.ColorReset, strings.Join(cfg.Tags, ", "))
+			}
+			if cfg.Author != "" {
+				shared.Printf("    %sAuthor:%s %s\n", shared.ColorGray, shared.ColorReset, cfg.Author)
+			}
+		}
+		shared.Printf("    %sMatched:%s %s\n", shared.ColorGray, shared.ColorReset, strings.Join(result.matched, ", "))
+
+		// Add extra spacing between templates
+		if i < len(results)-1 {
+			shared.Println()
+		}
+	}
+
+	return nil
+}
+
+// searchTemplates ranks the active templates against query, best match first
+// Each word of the query is scored against its best matching field
+func searchTemplates(templates []storage.Template, query string) []searchResult {
+	var results []searchResult
+	for _, tmpl := range templates {
+		if tmpl.Shadowed {
+			continue
+		}
+
+		cfg, _ := config.LoadConfig(tmpl.Path)
+		fields := []searchField{{label: "name", text: tmpl.Name, weight: 4}}
+		if cfg != nil {
+			fields = append(fields,
+				searchField{label: "tags", text: strings.Join(cfg.Tags, " "), weight: 3},
+				searchField{label: "description", text: cfg.Description, weight: 2},
+				searchField{label: "author", text: cfg.Author, weight: 2},
+			)
+			if cfg.Name != "" && cfg.Name != tmpl.Name {
+				fields = append(fields, searchField{label: "name", text: cfg.Name, weight: 3})
+			}
+		}
+
+		result, ok := scoreTemplate(fields, query)
+		if !ok {
+			continue
+		}
+		result.template = tmpl
+		result.config = cfg
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+	return results
+}
+
+// scoreTemplate matches every word of query against the best field
+// fields[0] is the template name, whose matched runes are kept for highlighting
+func scoreTemplate(fields []searchField, query string) (searchResult, bool) {
+	var result searchResult
+	seen := make(map[string]bool)
+
+	for _, word := range strings.Fields(query) {
+		best := -1
+		var bestMatch fuzzy.Result
+		for i, field := range fields {
+			match, ok := fuzzy.Match(word, field.text)
+			if ok && (best < 0 || match.Score*field.weight > bestMatch.Score*fields[best].weight) {
+				best, bestMatch = i, match
+			}
+		}
+		if best < 0 {
+			return searchResult{}, false
+		}
+
+		result.score += bestMatch.Score * fields[best].weight
+		if best == 0 {
+			result.positions = append(result.positions, bestMatch.Positions...)
+		}
+		if label := fields[best].label; !seen[label] {
+			seen[label] = true
+			result.matched = append(result.matched, label)
+		}
+	}
+
+	sort.Ints(result.positions)
+	return result, true
+}
@@ -1,27 +1,30 @@
 package template
 
 import (
-	"fmt"
-
 	"github.com/lancher-dev/lancher/internal/cli/shared"
 )
 
 // RunHelp displays help for template command
 func RunHelp() error {
-	fmt.Printf("%slancher template%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("Manage local templates\n\n")
+	shared.Printf("%slancher template%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
+	shared.Printf("Manage local templates\n\n")
 
-	fmt.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    lancher template <subcommand> [args...] [options]\n\n")
+	shared.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    lancher template <subcommand> [args...] [options]\n\n")
 
-	fmt.Printf("%sSUBCOMMANDS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    %s%-20s%s %s\n", shared.ColorGreen, "add", shared.ColorReset, "Add a new template")
-	fmt.Printf("    %slist%s, %sls%s             %s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "List all available templates")
-	fmt.Printf("    %supdate%s               %s\n", shared.ColorGreen, shared.ColorReset, "Update an existing template")
-	fmt.Printf("    %sremove%s, %srm%s           %s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "Remove a template")
+	shared.Printf("%sSUBCOMMANDS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s%-20s%s %s\n", shared.ColorGreen, "add", shared.ColorReset, "Add a new template")
+	shared.Printf("    %slist%s, %sls%s             %s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "List all available templates")
+	shared.Printf("    %ssearch%s               %s\n", shared.ColorGreen, shared.ColorReset, "Search templates by name, description, tags and author")
+	shared.Printf("    %sinfo%s                 %s\n", shared.ColorGreen, shared.ColorReset, "Show the details of a template")
+	shared.Printf("    %supdate%s               %s\n", shared.ColorGreen, shared.ColorReset, "Update an existing template")
+	shared.Printf("    %sls-files%s             %s\n", shared.ColorGreen, shared.ColorReset, "List the files a create would emit")
+	shared.Printf("    %sversions%s             %s\n", shared.ColorGreen, shared.ColorReset, "List stored versions of a template")
+	shared.Printf("    %srollback%s             %s\n", shared.ColorGreen, shared.ColorReset, "Restore a template from a snapshot")
+	shared.Printf("    %sremove%s, %srm%s           %s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "Remove a template")
 
-	fmt.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    %s-h%s, %s--help%s           %s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "Show help for any subcommand")
+	shared.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s-h%s, %s--help%s           %s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "Show help for any subcommand")
 
 	return nil
 }
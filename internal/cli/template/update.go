@@ -3,46 +3,72 @@ package template
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 
 	"github.com/lancher-dev/lancher/internal/cli/shared"
+	"github.com/lancher-dev/lancher/internal/config"
 	"github.com/lancher-dev/lancher/internal/fileutil"
+	"github.com/lancher-dev/lancher/internal/gitutil"
+	"github.com/lancher-dev/lancher/internal/registry"
+	"github.com/lancher-dev/lancher/internal/sources"
 	"github.com/lancher-dev/lancher/internal/storage"
 )
 
 // RunUpdateHelp displays help for template update command
 func RunUpdateHelp() error {
-	fmt.Printf("%slancher template update%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("Update an existing template\n\n")
-
-	fmt.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    lancher template update <name> [options]\n\n")
-
-	fmt.Printf("%sARGS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    %s%-15s%s %s\n\n", shared.ColorGreen, "name", shared.ColorReset, "Template name to update")
-
-	fmt.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
-	fmt.Printf("    %s-d%s %s<path>%s     %sOverwrite with files from this path%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
-	fmt.Printf("    %s-p%s, %s--print%s  %sShow detailed output (no spinner)%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
-	fmt.Printf("    %s-h%s, %s--help%s   %sShow this help message%s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("%slancher template update%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
+	shared.Printf("Update an existing template\n\n")
+
+	shared.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    lancher template update <name> [options]\n\n")
+
+	shared.Printf("%sDESCRIPTION:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    Git templates are updated with git pull, or re-fetched at their pinned\n")
+	shared.Printf("    ref when added with --ref. Templates added from a local directory or a\n")
+	shared.Printf("    archive, or from a repository subdirectory, are re-synced from their\n")
+	shared.Printf("    recorded source. Archive URLs are downloaded again and verified against\n")
+	shared.Printf("    their pinned checksum.\n\n")
+	shared.Printf("    The previous state is kept as a snapshot; use %slancher template rollback%s\n", shared.ColorGreen, shared.ColorReset)
+	shared.Printf("    to restore it.\n\n")
+
+	shared.Printf("%sARGS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s%-15s%s %s\n\n", shared.ColorGreen, "name", shared.ColorReset, "Template name to update")
+
+	shared.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s-d%s %s<path>%s                   %sOverwrite with files from this path%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --ref%s %s<ref>%s             %sMove a git template to a branch, tag or commit%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --sha256%s %s<hash>%s         %sPin an archive URL template to a new checksum%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --respect-gitignore%s     %sSkip files ignored by git when using -d%s\n", shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s    --no-respect-gitignore%s  %sCopy git-ignored files with -d even if enabled in config%s\n", shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s-p%s, %s--print%s                 %sShow detailed output (no spinner)%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+	shared.Printf("    %s-h%s, %s--help%s                  %sShow this help message%s\n\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
 
 	return nil
 }
 
 // runUpdate updates a template
 func RunUpdate(args []string) error {
-	var overwritePath string
+	var overwritePath, ref, checksum string
 	var templateName string
-	var verbose bool
+	var verbose, respectGitignore, noRespectGitignore bool
 
 	// Parse args and flags
 	for i := 0; i < len(args); i++ {
 		if args[i] == "-d" && i+1 < len(args) {
 			overwritePath = args[i+1]
 			i++
+		} else if args[i] == "--ref" && i+1 < len(args) {
+			ref = args[i+1]
+			i++
+		} else if args[i] == "--sha256" && i+1 < len(args) {
+			checksum = args[i+1]
+			i++
 		} else if args[i] == "-p" || args[i] == "--print" {
 			verbose = true
+		} else if args[i] == "--respect-gitignore" {
+			respectGitignore = true
+		} else if args[i] == "--no-respect-gitignore" {
+			noRespectGitignore = true
 		} else if templateName == "" {
 			templateName = args[i]
 		}
@@ -52,19 +78,18 @@ func RunUpdate(args []string) error {
 		usage := "USAGE:\n    lancher template update <name> [OPTIONS]"
 		return shared.FormatMissingArgsError([]string{"name"}, usage)
 	}
+	if respectGitignore && noRespectGitignore {
+		return shared.FormatError("cannot use both --respect-gitignore and --no-respect-gitignore flags")
+	}
 
 	// Validate template name
 	if err := shared.SanitizeTemplateName(templateName); err != nil {
 		return shared.FormatError(err.Error())
 	}
 
-	// Check if template exists
-	exists, err := storage.TemplateExists(templateName)
-	if err != nil {
-		return shared.FormatError(fmt.Sprintf("failed to check template: %v", err))
-	}
-	if !exists {
-		return shared.FormatError(fmt.Sprintf("template '%s' not found", templateName))
+	// Check if template exists in the user store
+	if err := checkUserTemplate(templateName); err != nil {
+		return err
 	}
 
 	// Get template path
@@ -73,8 +98,49 @@ func RunUpdate(args []string) error {
 		return shared.FormatError(fmt.Sprintf("failed to get template path: %v", err))
 	}
 
+	// Linked templates are read in place, so there is nothing to update
+	if target, linked := storage.LinkTarget(templatePath); linked {
+		if overwritePath != "" || ref != "" || checksum != "" {
+			return shared.FormatError(fmt.Sprintf("template '%s' is linked to %s and cannot be overwritten\nRemove it and add it again to change the source", templateName, target))
+		}
+		shared.Printf("%s✓ Template '%s' is linked, changes apply immediately%s\n", shared.ColorGreen, templateName, shared.ColorReset)
+		shared.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, target)
+		return nil
+	}
+
+	if ref != "" && overwritePath != "" {
+		return shared.FormatError("cannot use --ref together with -d")
+	}
+	if checksum != "" {
+		if overwritePath != "" {
+			return shared.FormatError("cannot use --sha256 together with -d")
+		}
+		if checksum, err = sources.NormalizeSHA256(checksum); err != nil {
+			return shared.FormatError(err.Error())
+		}
+	}
+
+	// Keep the current state so earlier versions stay available
+	snapshot, err := snapshotTemplate(templateName, templatePath)
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to snapshot template: %v", err))
+	}
+
+	err = updateTemplate(templateName, templatePath, overwritePath, ref, checksum, verbose, respectGitignore, noRespectGitignore)
+	settleSnapshot(snapshot, templatePath, err)
+	return err
+}
+
+// updateTemplate updates a stored template from a path, its git remote or its recorded source
+func updateTemplate(templateName, templatePath, overwritePath, ref, checksum string, verbose, respectGitignore, noRespectGitignore bool) error {
 	// If -d flag is provided, overwrite with new path
 	if overwritePath != "" {
+		userCfg, err := config.LoadUserConfig()
+		if err != nil {
+			return shared.FormatError(fmt.Sprintf("failed to load user config: %v", err))
+		}
+		respect := respectGitignore || (!noRespectGitignore && userCfg.Add.RespectGitignore)
+
 		sourceAbs, err := filepath.Abs(overwritePath)
 		if err != nil {
 			return shared.FormatError(fmt.Sprintf("invalid source path: %v", err))
@@ -84,44 +150,78 @@ func RunUpdate(args []string) error {
 			return shared.FormatError(fmt.Sprintf("source directory does not exist: %s", sourceAbs))
 		}
 
-		fmt.Printf("%sRemoving old template...%s\n", shared.ColorYellow, shared.ColorReset)
-		if err := fileutil.RemoveDir(templatePath); err != nil {
-			return shared.FormatError(fmt.Sprintf("failed to remove old template: %v", err))
-		}
-
-		fmt.Printf("%sCopying new template...%s\n", shared.ColorYellow, shared.ColorReset)
-		if err := fileutil.CopyDir(sourceAbs, templatePath); err != nil {
+		// The new copy is staged first, so a failed copy leaves the old template in place
+		shared.Printf("%sCopying new template...%s\n", shared.ColorYellow, shared.ColorReset)
+		diff, err := replaceTemplate(templatePath, func(staging string) error {
+			return fileutil.CopyTree(sourceAbs, staging, addIgnoreOptions(respect))
+		})
+		if err != nil {
 			return shared.FormatError(fmt.Sprintf("failed to copy new template: %v", err))
 		}
 
-		fmt.Printf("%s✓ Template '%s' updated from path%s\n", shared.ColorGreen, templateName, shared.ColorReset)
-		fmt.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, sourceAbs)
-		fmt.Printf("  %sStored:%s %s\n", shared.ColorYellow, shared.ColorReset, templatePath)
+		shared.Printf("%s✓ Template '%s' updated from path%s\n", shared.ColorGreen, templateName, shared.ColorReset)
+		shared.Printf("  %sSource:%s %s\n", shared.ColorYellow, shared.ColorReset, sourceAbs)
+		shared.Printf("  %sStored:%s %s\n", shared.ColorYellow, shared.ColorReset, templatePath)
+		printTreeDiff(diff, verbose)
+
+		entry := &registry.Entry{SourceType: registry.SourceLocal, Source: sourceAbs, RespectGitignore: respect}
+		if err := saveMetadata(templateName, templatePath, entry); err != nil {
+			warnMetadata(err)
+		}
 
 		return nil
 	}
 
-	// Otherwise, try git pull
-	gitDir := filepath.Join(templatePath, ".git")
-	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
-		return shared.FormatError(fmt.Sprintf("template '%s' is not a git repository\nUse -d <path> to overwrite with new files", templateName))
+	// The recorded source decides how to update: local directories, archives and repository
+	// subdirectories are re-synced, whole repositories are pulled. A .git directory copied
+	// from a local checkout or an archive does not make a template pullable
+	entry, metaErr := loadMetadata(templateName)
+	if entry != nil && (!entry.IsGit() || entry.Subdir != "") {
+		return resyncTemplate(templateName, templatePath, ref, checksum, verbose)
+	}
+	// Templates added before metadata existed are pulled if they were cloned from git
+	if entry == nil && !gitutil.IsRepository(templatePath) {
+		return resyncTemplate(templateName, templatePath, ref, checksum, verbose)
+	}
+	if checksum != "" {
+		return shared.FormatError(fmt.Sprintf("template '%s' is a git repository, --sha256 cannot be used", templateName))
+	}
+
+	// Keep the recorded source, or reconstruct it for templates added before metadata existed
+	if metaErr == nil && entry == nil {
+		remote, _ := gitutil.RemoteURL(templatePath)
+		entry = &registry.Entry{SourceType: registry.SourceGit, Source: remote}
+	}
+
+	// A pinned template is re-fetched at its ref instead of pulled
+	branch, _ := gitutil.CurrentBranch(templatePath)
+	if ref == "" && branch == "" && entry != nil {
+		ref = entry.Ref
 	}
 
 	var spinner *shared.Spinner
 	writer := shared.NewSpinnerWriter(verbose)
 
+	message := "Pulling latest changes..."
+	if ref != "" {
+		message = fmt.Sprintf("Fetching %s...", ref)
+	}
 	if !verbose {
-		spinner = shared.NewSpinner("Pulling latest changes...")
+		spinner = shared.NewSpinner(message)
 		spinner.Start()
 		defer spinner.Stop()
 	} else {
-		fmt.Printf("%sPulling latest changes...%s\n", shared.ColorYellow, shared.ColorReset)
+		shared.Printf("%s%s%s\n", shared.ColorYellow, message, shared.ColorReset)
 	}
 
-	cmd := exec.Command("git", "-C", templatePath, "pull")
-	cmd.Stdout = writer.MultiWriter()
-	cmd.Stderr = writer.MultiWriter()
-	if err := cmd.Run(); err != nil {
+	if ref != "" {
+		if err := gitutil.Checkout(templatePath, ref, writer.MultiWriter()); err != nil {
+			if spinner != nil {
+				spinner.Fail(fmt.Sprintf("Failed to check out %s: %v", ref, err))
+			}
+			return shared.FormatError(fmt.Sprintf("failed to check out '%s': %v", ref, err))
+		}
+	} else if err := gitutil.Pull(templatePath, writer.MultiWriter()); err != nil {
 		if spinner != nil {
 			spinner.Fail(fmt.Sprintf("Git pull failed: %v", err))
 		}
@@ -131,9 +231,23 @@ func RunUpdate(args []string) error {
 	if spinner != nil {
 		spinner.Success(fmt.Sprintf("Template '%s' updated successfully", templateName))
 	} else {
-		fmt.Printf("%s✓ Template '%s' updated successfully%s\n", shared.ColorGreen, templateName, shared.ColorReset)
+		shared.Printf("%s✓ Template '%s' updated successfully%s\n", shared.ColorGreen, templateName, shared.ColorReset)
+	}
+	shared.Printf("  %sLocation:%s %s\n", shared.ColorYellow, shared.ColorReset, templatePath)
+	if ref != "" {
+		shared.Printf("  %sRef:%s %s\n", shared.ColorYellow, shared.ColorReset, ref)
+	}
+
+	if metaErr != nil {
+		warnMetadata(metaErr)
+		return nil
+	}
+	if ref != "" {
+		entry.Ref = ref
+	}
+	if err := saveMetadata(templateName, templatePath, entry); err != nil {
+		warnMetadata(err)
 	}
-	fmt.Printf("  %sLocation:%s %s\n", shared.ColorYellow, shared.ColorReset, templatePath)
 
 	return nil
 }
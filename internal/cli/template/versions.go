@@ -0,0 +1,136 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lancher-dev/lancher/internal/cli/shared"
+	"github.com/lancher-dev/lancher/internal/config"
+	"github.com/lancher-dev/lancher/internal/fileutil"
+	"github.com/lancher-dev/lancher/internal/gitutil"
+	"github.com/lancher-dev/lancher/internal/storage"
+	"github.com/lancher-dev/lancher/internal/versions"
+)
+
+// RunVersionsHelp displays help for template versions command
+func RunVersionsHelp() error {
+	shared.Printf("%slancher template versions%s\n", shared.ColorGreen+shared.ColorBold, shared.ColorReset)
+	shared.Printf("List the stored versions of a template\n\n")
+
+	shared.Printf("%sUSAGE:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    lancher template versions <name>\n\n")
+
+	shared.Printf("%sDESCRIPTION:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    A snapshot is stored before each template update. Git templates also\n")
+	shared.Printf("    list the tags published by their remote. Any of them can be used with\n")
+	shared.Printf("    %slancher create -t <name>@<version>%s.\n\n", shared.ColorCyan, shared.ColorReset)
+
+	shared.Printf("%sARGS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s%-15s%s %s\n\n", shared.ColorGreen, "name", shared.ColorReset, "Template name")
+
+	shared.Printf("%sOPTIONS:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+	shared.Printf("    %s-h%s, %s--help%s  %sShow this help message%s\n", shared.ColorGreen, shared.ColorReset, shared.ColorGreen, shared.ColorReset, "", "")
+
+	return nil
+}
+
+// RunVersions lists the current version, stored versions and remote tags of a template
+func RunVersions(args []string) error {
+	if len(args) == 0 {
+		usage := "USAGE:\n    lancher template versions <name>"
+		return shared.FormatMissingArgsError([]string{"name"}, usage)
+	}
+	templateName := args[0]
+
+	// Validate template name
+	if err := shared.SanitizeTemplateName(templateName); err != nil {
+		return shared.FormatError(err.Error())
+	}
+
+	tmpl, found, err := storage.FindTemplate(templateName)
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to check template: %v", err))
+	}
+	if !found {
+		return shared.FormatNotFoundError(templateName)
+	}
+
+	root, err := storage.GetTemplatesDir()
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to get templates directory: %v", err))
+	}
+	stored, err := versions.List(root, tmpl.QualifiedName())
+	if err != nil {
+		return shared.FormatError(fmt.Sprintf("failed to list versions: %v", err))
+	}
+
+	shared.Printf("%sVersions of '%s':%s\n\n", shared.ColorCyan+shared.ColorBold, templateName, shared.ColorReset)
+
+	// Current version
+	var label, commit string
+	if cfg, _ := config.LoadConfig(tmpl.Path); cfg != nil {
+		label = cfg.Version
+	}
+	if gitutil.IsRepository(tmpl.Path) {
+		if head, err := gitutil.HeadCommit(tmpl.Path); err == nil && len(head) > 7 {
+			commit = head[:7]
+		}
+	}
+	printVersionLine("current", "", label, commit, "")
+
+	for _, v := range stored {
+		printVersionLine(v.ID, v.Kind, v.Label, v.ShortCommit(), v.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+
+	// Tags published by the remote of git templates
+	if gitutil.IsRepository(tmpl.Path) {
+		tags, err := gitutil.RemoteTags(tmpl.Path)
+		if err != nil {
+			shared.Printf("\n%s⚠ Failed to list remote tags: %v%s\n", shared.ColorYellow, err, shared.ColorReset)
+		} else if len(tags) > 0 {
+			shared.Printf("\n%sRemote tags:%s\n", shared.ColorCyan+shared.ColorBold, shared.ColorReset)
+			shared.Printf("  %s\n", strings.Join(tags, ", "))
+		}
+	}
+
+	shared.Printf("\n%sUse with:%s lancher create -t %s@<version>\n", shared.ColorGray, shared.ColorReset, templateName)
+
+	return nil
+}
+
+// printVersionLine prints a single row of the versions listing
+func printVersionLine(id, kind, label, commit, date string) {
+	if label == "" {
+		label = "-"
+	}
+	shared.Printf("  %s%-20s%s %-9s %-12s %-8s %s%s%s\n", shared.ColorGreen, id, shared.ColorReset,
+		kind, label, commit, shared.ColorGray, date, shared.ColorReset)
+}
+
+// snapshotTemplate stores the current state of a template in the user store before it is updated
+func snapshotTemplate(name, templatePath string) (*versions.Version, error) {
+	root, err := storage.GetTemplatesDir()
+	if err != nil {
+		return nil, err
+	}
+	entry, err := loadMetadata(name)
+	if err != nil {
+		return nil, err
+	}
+	return versions.Snapshot(root, name, templatePath, entry)
+}
+
+// settleSnapshot drops the snapshot if the update left the template unchanged, files and HEAD commit
+// Otherwise the snapshot ID is reported so the previous state can be found again
+func settleSnapshot(snapshot *versions.Version, templatePath string, updateErr error) {
+	if hash, err := fileutil.HashDir(templatePath); err == nil && hash == snapshot.Hash && headCommit(templatePath) == snapshot.Commit {
+		versions.Discard(snapshot)
+		return
+	}
+
+	if updateErr != nil {
+		shared.Printf("%s⚠ Previous state kept as snapshot %s%s\n", shared.ColorYellow, snapshot.ID, shared.ColorReset)
+		return
+	}
+	shared.Printf("  %sSnapshot:%s %s (previous version)\n", shared.ColorYellow, shared.ColorReset, snapshot.ID)
+}
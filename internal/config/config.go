@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/lancher-dev/lancher/internal/ignore"
 	"gopkg.in/yaml.v3"
 )
 
@@ -18,12 +20,14 @@ var ConfigFileNames = []string{
 
 // Config represents the lancher configuration file
 type Config struct {
-	Name        string   `yaml:"name"`
-	Description string   `yaml:"description"`
-	Author      string   `yaml:"author"`
-	Version     string   `yaml:"version"`
-	Hooks       []string `yaml:"hooks"`
-	Ignore      []string `yaml:"ignore"`
+	Name        string   `yaml:"name" json:"name,omitempty"`
+	Description string   `yaml:"description" json:"description,omitempty"`
+	Author      string   `yaml:"author" json:"author,omitempty"`
+	Version     string   `yaml:"version" json:"version,omitempty"`
+	Category    string   `yaml:"category" json:"category,omitempty"`
+	Tags        []string `yaml:"tags" json:"tags,omitempty"`
+	Hooks       []string `yaml:"hooks" json:"hooks,omitempty"`
+	Ignore      []string `yaml:"ignore" json:"ignore,omitempty"`
 }
 
 // LoadResult contains the loaded config and metadata about the loading process
@@ -78,23 +82,77 @@ func LoadConfigWithDetails(templatePath string) *LoadResult {
 }
 
 // ShouldIgnore checks if a file should be ignored during template creation
+// Patterns use gitignore syntax and are matched against the path itself
 func (c *Config) ShouldIgnore(relativePath string) bool {
 	if c == nil {
 		return false
 	}
 
-	for _, pattern := range c.Ignore {
-		if matched, _ := filepath.Match(pattern, relativePath); matched {
-			return true
-		}
-		// Also check if pattern matches any part of the path
-		if matched, _ := filepath.Match(pattern, filepath.Base(relativePath)); matched {
+	return ignore.NewMatcher(c.Ignore).Match(relativePath, false)
+}
+
+// IgnoreOptions returns the rules applied when creating a project from a template:
+// .git and the template's own config file at its root are always excluded, then the
+// ignore patterns from the config and any .lancherignore files in the template apply
+func (c *Config) IgnoreOptions() ignore.Options {
+	opts := ignore.Options{
+		Files:   []string{ignore.LancherIgnoreFile},
+		Exclude: []string{".git"},
+	}
+	for _, name := range ConfigFileNames {
+		opts.Exclude = append(opts.Exclude, "/"+name)
+	}
+	if c != nil {
+		opts.Patterns = c.Ignore
+	}
+	return opts
+}
+
+// HasTag reports whether the config has the given tag (case-insensitive)
+func (c *Config) HasTag(tag string) bool {
+	if c == nil {
+		return false
+	}
+
+	for _, t := range c.Tags {
+		if strings.EqualFold(t, tag) {
 			return true
 		}
 	}
 	return false
 }
 
+// MatchesFilter reports whether the config has all the given tags and,
+// if category is not empty, belongs to that category (case-insensitive)
+// An empty filter matches every template, including those without config
+func (c *Config) MatchesFilter(tags []string, category string) bool {
+	if category != "" && (c == nil || !strings.EqualFold(c.Category, category)) {
+		return false
+	}
+	for _, tag := range tags {
+		if !c.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetCategory returns the template category, or an empty string if none is set
+func (c *Config) GetCategory() string {
+	if c == nil {
+		return ""
+	}
+	return c.Category
+}
+
+// GetDescription returns the template description, or an empty string if none is set
+func (c *Config) GetDescription() string {
+	if c == nil {
+		return ""
+	}
+	return c.Description
+}
+
 // HasHooks returns true if config has hooks defined
 func (c *Config) HasHooks() bool {
 	return c != nil && len(c.Hooks) > 0
@@ -119,5 +177,11 @@ func (c *Config) GetMetadata() string {
 	if c.Version != "" {
 		metadata += fmt.Sprintf("Version: %s\n", c.Version)
 	}
+	if c.Category != "" {
+		metadata += fmt.Sprintf("Category: %s\n", c.Category)
+	}
+	if len(c.Tags) > 0 {
+		metadata += fmt.Sprintf("Tags: %s\n", strings.Join(c.Tags, ", "))
+	}
 	return metadata
 }
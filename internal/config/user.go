@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UserConfigFileName is the name of the user-level configuration file
+const UserConfigFileName = "config.yaml"
+
+// Hooks policies for the create command
+const (
+	HooksPrompt = "prompt"
+	HooksAlways = "always"
+	HooksNever  = "never"
+)
+
+// UserConfig represents the user-level configuration file
+type UserConfig struct {
+	TemplatesDir string            `yaml:"templates_dir"`
+	Aliases      map[string]string `yaml:"aliases"`
+	Create       CreateDefaults    `yaml:"create"`
+	Add          AddDefaults       `yaml:"add"`
+}
+
+// AddDefaults holds default values applied by the template add command
+type AddDefaults struct {
+	RespectGitignore bool `yaml:"respect_gitignore"`
+}
+
+// CreateDefaults holds default values applied by the create command
+type CreateDefaults struct {
+	Destination string            `yaml:"destination"`
+	Git         *bool             `yaml:"git"`
+	Hooks       string            `yaml:"hooks"`
+	Author      string            `yaml:"author"`
+	Variables   map[string]string `yaml:"variables"`
+}
+
+// GetUserConfigDir returns the directory holding the user configuration
+// ($XDG_CONFIG_HOME/lancher or ~/.config/lancher)
+func GetUserConfigDir() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "lancher"), nil
+}
+
+// GetUserConfigPath returns the full path of the user configuration file
+func GetUserConfigPath() (string, error) {
+	dir, err := GetUserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, UserConfigFileName), nil
+}
+
+// LoadUserConfig loads the user configuration file
+// Returns an empty configuration if the file does not exist
+func LoadUserConfig() (*UserConfig, error) {
+	configPath, err := GetUserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return &UserConfig{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+
+	var cfg UserConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", configPath, err)
+	}
+
+	switch cfg.Create.Hooks {
+	case "", HooksPrompt, HooksAlways, HooksNever:
+	default:
+		return nil, fmt.Errorf("invalid hooks policy '%s' in %s (expected %s, %s or %s)",
+			cfg.Create.Hooks, configPath, HooksPrompt, HooksAlways, HooksNever)
+	}
+
+	// Resolve templates_dir relative to the config file location
+	if cfg.TemplatesDir != "" {
+		cfg.TemplatesDir = ExpandPath(cfg.TemplatesDir, filepath.Dir(configPath))
+	}
+
+	return &cfg, nil
+}
+
+// ExpandPath expands environment variables and a leading ~ in path
+// Relative paths are resolved against baseDir
+func ExpandPath(path, baseDir string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	if !filepath.IsAbs(path) && baseDir != "" {
+		path = filepath.Join(baseDir, path)
+	}
+	return filepath.Clean(path)
+}
+
+// ExpandAlias expands a custom source alias (e.g. "work:team/repo")
+// The alias value is prepended to the remainder of the source
+// Sources without a configured alias are returned unchanged
+func (u *UserConfig) ExpandAlias(source string) string {
+	if u == nil {
+		return source
+	}
+
+	prefix, rest, found := strings.Cut(source, ":")
+	if !found {
+		return source
+	}
+
+	if target, ok := u.Aliases[prefix]; ok {
+		return target + rest
+	}
+	return source
+}
+
+// HookEnv returns environment variables exposing the default author and
+// variable answers to hooks (LANCHER_AUTHOR, LANCHER_VAR_<NAME>)
+func (u *UserConfig) HookEnv() []string {
+	if u == nil {
+		return nil
+	}
+
+	var env []string
+	if u.Create.Author != "" {
+		env = append(env, "LANCHER_AUTHOR="+u.Create.Author)
+	}
+	names := make([]string, 0, len(u.Create.Variables))
+	for name := range u.Create.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	replacer := strings.NewReplacer("-", "_", ".", "_", " ", "_")
+	for _, name := range names {
+		key := strings.ToUpper(replacer.Replace(name))
+		env = append(env, "LANCHER_VAR_"+key+"="+u.Create.Variables[name])
+	}
+	return env
+}
@@ -0,0 +1,497 @@
+package fileutil
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Archive formats recognized by DetectArchive
+const (
+	FormatZip     = "zip"
+	FormatTar     = "tar"
+	FormatTarGzip = "tar.gz"
+	FormatTarXz   = "tar.xz"
+	FormatTarZstd = "tar.zst"
+	FormatTarBz2  = "tar.bz2"
+)
+
+// Extraction limits used when ExtractOptions leaves them unset
+const (
+	DefaultMaxExtractSize  = 1 << 30 // 1 GiB
+	DefaultMaxExtractFiles = 100000
+)
+
+// ErrNotArchive is returned when a file is not in a supported archive format
+var ErrNotArchive = errors.New("not a supported archive (zip, tar, tar.gz, tar.xz, tar.zst, tar.bz2)")
+
+// tarMagicOffset is where the "ustar" magic of a tar header starts
+const tarMagicOffset = 257
+
+// ExtractOptions control archive extraction
+// The zero value applies the default limits and extracts the archive as laid out;
+// stripping a wrapping directory is opt-in, as it cannot be told apart from a template
+// that is a single directory
+type ExtractOptions struct {
+	MaxSize       int64 // Maximum total uncompressed size in bytes
+	MaxFiles      int   // Maximum number of entries
+	StripTopLevel bool  // Move the contents of a single top-level directory (e.g. repo-main/) up into the destination
+}
+
+// SizeLimit returns the maximum total size allowed by the options
+func (o ExtractOptions) SizeLimit() int64 {
+	if o.MaxSize > 0 {
+		return o.MaxSize
+	}
+	return DefaultMaxExtractSize
+}
+
+// extractor writes archive entries into a destination directory within the configured limits
+type extractor struct {
+	dest     string
+	size     int64
+	files    int
+	maxSize  int64
+	maxFiles int
+}
+
+// newExtractor prepares extraction into dest, creating it if needed
+func newExtractor(dest string, opts ExtractOptions) (*extractor, error) {
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
+	}
+	// Containment checks compare real paths, so resolve links leading to dest itself
+	realDest, err := filepath.EvalSymlinks(dest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve destination directory: %w", err)
+	}
+	e := &extractor{dest: realDest, maxSize: opts.SizeLimit(), maxFiles: opts.MaxFiles}
+	if e.maxFiles <= 0 {
+		e.maxFiles = DefaultMaxExtractFiles
+	}
+	return e, nil
+}
+
+// entry counts an archive entry against the limit and returns its destination
+// Returns an empty path for the archive root and an error for entries escaping dest (zip slip),
+// either by name or through a symlink extracted earlier. A symlink already at the destination
+// is removed, so the entry replaces it instead of writing through it
+func (e *extractor) entry(name string) (string, error) {
+	e.files++
+	if e.files > e.maxFiles {
+		return "", fmt.Errorf("archive has more than %d entries", e.maxFiles)
+	}
+
+	destPath := filepath.Join(e.dest, name)
+	if destPath == e.dest {
+		return "", nil
+	}
+	if !e.inside(destPath) {
+		return "", fmt.Errorf("illegal file path in archive: %s", name)
+	}
+	if err := e.checkParents(destPath); err != nil {
+		return "", err
+	}
+	if info, err := os.Lstat(destPath); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+		if err := os.Remove(destPath); err != nil {
+			return "", err
+		}
+	}
+	return destPath, nil
+}
+
+// inside reports whether path lies within the destination directory
+func (e *extractor) inside(path string) bool {
+	return strings.HasPrefix(path, e.dest+string(os.PathSeparator))
+}
+
+// contains reports whether path is the destination directory or lies within it
+func (e *extractor) contains(path string) bool {
+	return path == e.dest || e.inside(path)
+}
+
+// checkParents returns an error if a directory leading to path is a symlink
+// path must be clean and inside dest: with no symlink among its parents, it is its own real path
+func (e *extractor) checkParents(path string) error {
+	rel, err := filepath.Rel(e.dest, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return err
+	}
+
+	current := e.dest
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("illegal file path in archive through symlink %s: %s", e.rel(current), e.rel(path))
+		}
+	}
+	return nil
+}
+
+// dir creates a directory entry
+func (e *extractor) dir(path string, perm fs.FileMode) error {
+	if err := os.MkdirAll(path, perm|0700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return nil
+}
+
+// file writes a regular file entry, counting its size against the limit
+func (e *extractor) file(path string, r io.Reader, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	// Read one byte past the remaining budget to detect archives exceeding it
+	n, err := io.Copy(file, io.LimitReader(r, e.maxSize-e.size+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	e.size += n
+	if e.size > e.maxSize {
+		return fmt.Errorf("archive exceeds the maximum extracted size of %d bytes", e.maxSize)
+	}
+	return nil
+}
+
+// symlink creates a symlink entry
+// Only relative targets resolving inside the destination are allowed. The target is
+// resolved through links extracted earlier (a/.. is not the directory when a is a link);
+// targets that do not exist yet are checked again by checkLinks once extraction is done
+func (e *extractor) symlink(path, target string) error {
+	illegal := fmt.Errorf("illegal symlink in archive: %s -> %s", e.rel(path), target)
+	if filepath.IsAbs(target) || !e.contains(filepath.Join(filepath.Dir(path), target)) {
+		return illegal
+	}
+	if real, err := filepath.EvalSymlinks(filepath.Dir(path) + string(os.PathSeparator) + target); err == nil && !e.contains(real) {
+		return illegal
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+	os.Remove(path)
+	return os.Symlink(target, path)
+}
+
+// checkLinks returns an error if an extracted symlink resolves outside the destination
+// Links are checked when created, but a link may point through entries extracted after it
+func (e *extractor) checkLinks() error {
+	return filepath.WalkDir(e.dest, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.Type()&fs.ModeSymlink == 0 {
+			return err
+		}
+		if real, err := filepath.EvalSymlinks(path); err == nil && !e.contains(real) {
+			target, _ := os.Readlink(path)
+			return fmt.Errorf("illegal symlink in archive: %s -> %s", e.rel(path), target)
+		}
+		return nil
+	})
+}
+
+// rel returns path relative to the destination, for error messages
+func (e *extractor) rel(path string) string {
+	if rel, err := filepath.Rel(e.dest, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// stripTopLevel moves the contents of a single top-level directory up into the destination
+func (e *extractor) stripTopLevel() error {
+	entries, err := os.ReadDir(e.dest)
+	if err != nil {
+		return err
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return nil
+	}
+
+	// Move the directory aside first, it may contain an entry of the same name
+	top, err := os.MkdirTemp(e.dest, ".strip-*")
+	if err != nil {
+		return err
+	}
+	wrapped := filepath.Join(top, entries[0].Name())
+	if err := os.Rename(filepath.Join(e.dest, entries[0].Name()), wrapped); err != nil {
+		return err
+	}
+
+	children, err := os.ReadDir(wrapped)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if err := os.Rename(filepath.Join(wrapped, child.Name()), filepath.Join(e.dest, child.Name())); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(top)
+}
+
+// detectFormat identifies an archive from its leading bytes
+// Compressed streams are assumed to contain a tar archive
+func detectFormat(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return FormatZip
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return FormatTarGzip
+	case bytes.HasPrefix(header, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}):
+		return FormatTarXz
+	case bytes.HasPrefix(header, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return FormatTarZstd
+	case bytes.HasPrefix(header, []byte("BZh")):
+		return FormatTarBz2
+	case len(header) >= tarMagicOffset+5 && string(header[tarMagicOffset:tarMagicOffset+5]) == "ustar":
+		return FormatTar
+	}
+	return ""
+}
+
+// DetectArchive returns the format of an archive file from its magic bytes
+// The file extension is ignored
+func DetectArchive(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	header := make([]byte, tarMagicOffset+8)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+
+	format := detectFormat(header[:n])
+	if format == "" {
+		return "", ErrNotArchive
+	}
+	return format, nil
+}
+
+// IsArchive reports whether path is a regular file in a supported archive format
+func IsArchive(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	_, err = DetectArchive(path)
+	return err == nil
+}
+
+// ExtractArchive extracts a ZIP or tar archive (optionally gzip, xz, zstd or bzip2
+// compressed) into an empty destination directory
+func ExtractArchive(archivePath, destDir string, opts ExtractOptions) error {
+	format, err := DetectArchive(archivePath)
+	if err != nil {
+		return err
+	}
+
+	e, err := newExtractor(destDir, opts)
+	if err != nil {
+		return err
+	}
+	if format == FormatZip {
+		err = e.unzip(archivePath)
+	} else {
+		err = e.untarFile(archivePath, format)
+	}
+	if err == nil {
+		err = e.checkLinks()
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.StripTopLevel {
+		return e.stripTopLevel()
+	}
+	return nil
+}
+
+// UnzipToDir extracts a ZIP file to the specified directory like ExtractArchive with
+// the default options
+func UnzipToDir(zipPath, destDir string) error {
+	return ExtractArchive(zipPath, destDir, ExtractOptions{})
+}
+
+// unzip extracts a ZIP file
+func (e *extractor) unzip(zipPath string) error {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to open zip file: %w", err)
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		destPath, err := e.entry(file.Name)
+		if err != nil {
+			return err
+		}
+		if destPath == "" {
+			continue
+		}
+
+		// Reject early when the declared sizes already exceed the limit
+		if file.UncompressedSize64 > uint64(e.maxSize-e.size) {
+			return fmt.Errorf("archive exceeds the maximum extracted size of %d bytes", e.maxSize)
+		}
+
+		mode := file.Mode()
+		switch {
+		case mode.IsDir():
+			err = e.dir(destPath, mode.Perm())
+		case mode&fs.ModeSymlink != 0:
+			err = e.unzipSymlink(file, destPath)
+		case mode.IsRegular():
+			err = e.unzipFile(file, destPath)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", file.Name, err)
+		}
+	}
+	return nil
+}
+
+// unzipFile extracts a single file from ZIP
+func (e *extractor) unzipFile(file *zip.File, destPath string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return e.file(destPath, src, file.Mode().Perm())
+}
+
+// unzipSymlink extracts a symlink from ZIP, whose contents are the link target
+func (e *extractor) unzipSymlink(file *zip.File, destPath string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	target, err := io.ReadAll(io.LimitReader(src, 4096))
+	if err != nil {
+		return err
+	}
+	return e.symlink(destPath, string(target))
+}
+
+// untarFile extracts a tar file, decompressing it according to format
+func (e *extractor) untarFile(archivePath, format string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = bufio.NewReader(file)
+	switch format {
+	case FormatTarGzip:
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read gzip stream: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	case FormatTarXz:
+		xr, err := xz.NewReader(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read xz stream: %w", err)
+		}
+		reader = xr
+	case FormatTarZstd:
+		zr, err := zstd.NewReader(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read zstd stream: %w", err)
+		}
+		defer zr.Close()
+		reader = zr
+	case FormatTarBz2:
+		reader = bzip2.NewReader(reader)
+	}
+
+	return e.untar(reader)
+}
+
+// untar extracts a tar stream
+// Device files, FIFOs and other special entries are skipped
+func (e *extractor) untar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		destPath, err := e.entry(header.Name)
+		if err != nil {
+			return err
+		}
+		if destPath == "" {
+			continue
+		}
+
+		perm := header.FileInfo().Mode().Perm()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = e.dir(destPath, perm)
+		case tar.TypeReg:
+			err = e.file(destPath, tr, perm)
+		case tar.TypeSymlink:
+			err = e.symlink(destPath, header.Linkname)
+		case tar.TypeLink:
+			err = e.hardlink(destPath, header.Linkname)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", header.Name, err)
+		}
+	}
+}
+
+// hardlink creates a hard link entry to a file extracted earlier
+// The target must not be reached through a symlink, which could lead outside the destination
+func (e *extractor) hardlink(path, target string) error {
+	targetPath := filepath.Join(e.dest, target)
+	if !e.inside(targetPath) || e.checkParents(targetPath) != nil {
+		return fmt.Errorf("illegal hard link in archive: %s -> %s", e.rel(path), target)
+	}
+	info, err := os.Lstat(targetPath)
+	if err != nil || !info.Mode().IsRegular() {
+		return fmt.Errorf("hard link target %s is not a file", target)
+	}
+	os.Remove(path)
+	return os.Link(targetPath, path)
+}
@@ -0,0 +1,328 @@
+package fileutil
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/lancher-dev/lancher/internal/ignore"
+)
+
+// SymlinkPolicy controls how CopyTreeWithLinks copies symlinks
+type SymlinkPolicy string
+
+// Symlink policies
+const (
+	// SymlinkPreserve recreates links whose target stays inside the copied tree;
+	// links leaving it would dangle in the copy, so their target is copied instead
+	SymlinkPreserve SymlinkPolicy = "preserve"
+	// SymlinkFollow copies the target of every link
+	SymlinkFollow SymlinkPolicy = "follow"
+	// SymlinkSkip leaves links out of the copy
+	SymlinkSkip SymlinkPolicy = "skip"
+)
+
+// copyBufferSize is the size of the buffers used when the kernel cannot copy a file itself
+const copyBufferSize = 256 * 1024
+
+// copyWorkers bounds the number of files copied concurrently
+var copyWorkers = min(runtime.NumCPU()*2, 16)
+
+// bufferPool holds copy buffers shared by all copies
+var bufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, copyBufferSize)
+		return &buf
+	},
+}
+
+// ParseSymlinkPolicy validates a symlink policy name; an empty name selects SymlinkPreserve
+func ParseSymlinkPolicy(name string) (SymlinkPolicy, error) {
+	switch policy := SymlinkPolicy(name); policy {
+	case "":
+		return SymlinkPreserve, nil
+	case SymlinkPreserve, SymlinkFollow, SymlinkSkip:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid symlink policy '%s' (expected %s, %s or %s)", name, SymlinkPreserve, SymlinkFollow, SymlinkSkip)
+}
+
+// CopyTree recursively copies a directory from src to dst, skipping paths ignored by opts
+// Symlinks are preserved as described by SymlinkPreserve
+func CopyTree(src, dst string, opts ignore.Options) error {
+	return CopyTreeWithLinks(src, dst, opts, SymlinkPreserve)
+}
+
+// CopyTreeWithLinks recursively copies a directory from src to dst, skipping paths ignored
+// by opts and copying symlinks according to links
+// The tree is walked once while a pool of workers copies the files; file and directory
+// permissions are kept regardless of the umask
+func CopyTreeWithLinks(src, dst string, opts ignore.Options, links SymlinkPolicy) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("failed to stat source: %w", err)
+	}
+	if !srcInfo.IsDir() {
+		return fmt.Errorf("source is not a directory: %s", src)
+	}
+
+	c := &copier{
+		dirModes: make(map[string]fs.FileMode),
+		jobs:     make(chan copyJob, copyWorkers),
+	}
+	for i := 0; i < copyWorkers; i++ {
+		c.workers.Add(1)
+		go c.work()
+	}
+
+	walkErr := c.mkdir(dst, srcInfo.Mode().Perm())
+	if walkErr == nil {
+		walkErr = walkTree(src, opts, links, func(kind treeEntryKind, relPath, srcPath string) error {
+			dstPath := filepath.Join(dst, relPath)
+			switch kind {
+			case treeDir:
+				info, err := os.Stat(srcPath)
+				if err != nil {
+					return fmt.Errorf("failed to stat source: %w", err)
+				}
+				return c.mkdir(dstPath, info.Mode().Perm())
+			case treeLink:
+				target, err := os.Readlink(srcPath)
+				if err != nil {
+					return fmt.Errorf("failed to read symlink: %w", err)
+				}
+				if err := os.Symlink(target, dstPath); err != nil {
+					return fmt.Errorf("failed to create symlink: %w", err)
+				}
+				return nil
+			}
+			return c.copyFile(srcPath, dstPath)
+		})
+	}
+	close(c.jobs)
+	c.workers.Wait()
+	if walkErr != nil {
+		return walkErr
+	}
+	if err := c.failed(); err != nil {
+		return err
+	}
+
+	// Directory permissions are applied last so read-only directories can still be filled
+	for dir, mode := range c.dirModes {
+		if err := os.Chmod(dir, mode); err != nil {
+			return fmt.Errorf("failed to set permissions: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListTree returns the files and symlinks CopyTreeWithLinks would create from src with the
+// same options, relative to src and in lexical order
+func ListTree(src string, opts ignore.Options, links SymlinkPolicy) ([]string, error) {
+	files := []string{}
+	err := walkTree(src, opts, links, func(kind treeEntryKind, relPath, srcPath string) error {
+		if kind != treeDir {
+			files = append(files, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// treeEntryKind is the kind of a path created by a tree copy
+type treeEntryKind int
+
+// Kinds of tree entries
+const (
+	treeDir  treeEntryKind = iota
+	treeFile               // Regular file, or the target of a followed symlink
+	treeLink               // Symlink recreated as is
+)
+
+// walkTree walks the tree at root as CopyTreeWithLinks copies it: ignored paths are left
+// out and symlinks are skipped, preserved or followed according to links
+// Followed links to directories are walked under their own path, so ignore rules match
+// paths as they appear in the copy rather than inside the link target
+// fn receives each path relative to root and the path its content is read from
+func walkTree(root string, opts ignore.Options, links SymlinkPolicy, fn func(kind treeEntryKind, relPath, srcPath string) error) error {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve source: %w", err)
+	}
+
+	// preserved reports whether the symlink at path is recreated instead of followed
+	preserved := func(path string) bool {
+		if links != SymlinkPreserve {
+			return false
+		}
+		target, err := os.Readlink(path)
+		if err != nil || filepath.IsAbs(target) {
+			return false
+		}
+		// The target is resolved through the links it crosses: with a -> ., a/.. is the
+		// parent of the tree, not the tree itself
+		resolved := realPath(filepath.Dir(path) + string(os.PathSeparator) + target)
+		return resolved == realRoot || strings.HasPrefix(resolved, realRoot+string(os.PathSeparator))
+	}
+
+	opts.Follow = func(relPath string) bool {
+		return links != SymlinkSkip && !preserved(filepath.Join(root, relPath))
+	}
+	return ignore.Walk(root, opts, func(relPath string, entry fs.DirEntry) error {
+		srcPath := filepath.Join(root, relPath)
+		if entry.IsDir() {
+			return fn(treeDir, relPath, srcPath)
+		}
+		if entry.Type()&fs.ModeSymlink == 0 {
+			return fn(treeFile, relPath, srcPath)
+		}
+
+		// Links to directories that are followed were passed as directories
+		if links == SymlinkSkip {
+			return nil
+		}
+		if preserved(srcPath) {
+			return fn(treeLink, relPath, srcPath)
+		}
+		info, err := os.Stat(srcPath)
+		if err != nil {
+			return fmt.Errorf("broken symlink %s: %w", srcPath, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("failed to follow symlink %s", srcPath)
+		}
+		return fn(treeFile, relPath, srcPath)
+	})
+}
+
+// copyJob is a file copied by a worker
+type copyJob struct {
+	src, dst string
+}
+
+// copier holds the state of a single CopyTreeWithLinks
+// Directories and symlinks are created by the walking goroutine, files by the workers
+type copier struct {
+	dirModes map[string]fs.FileMode // Permissions of the created directories
+
+	jobs    chan copyJob
+	workers sync.WaitGroup
+	mu      sync.Mutex
+	err     error // First error reported by a worker
+}
+
+// work copies files until the job channel is closed
+// After a failure the remaining jobs are drained without copying
+func (c *copier) work() {
+	defer c.workers.Done()
+	for job := range c.jobs {
+		if c.failed() != nil {
+			continue
+		}
+		if err := CopyFile(job.src, job.dst); err != nil {
+			c.mu.Lock()
+			if c.err == nil {
+				c.err = err
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+// failed returns the first error reported by a worker
+func (c *copier) failed() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// copyFile queues a file copy, stopping the walk once a worker has failed
+func (c *copier) copyFile(src, dst string) error {
+	if err := c.failed(); err != nil {
+		return err
+	}
+	c.jobs <- copyJob{src: src, dst: dst}
+	return nil
+}
+
+// mkdir creates the directory dst, writable until its permissions are applied
+func (c *copier) mkdir(dst string, perm fs.FileMode) error {
+	if err := os.MkdirAll(dst, perm|0700); err != nil {
+		return fmt.Errorf("failed to create destination: %w", err)
+	}
+	c.dirModes[dst] = perm
+	return nil
+}
+
+// realPath resolves the symlinks in path like filepath.EvalSymlinks
+// Missing trailing components are joined to the real path of their existing parent, so
+// the target of a dangling link can still be located
+func realPath(path string) string {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	// Split without cleaning, which would apply ".." before resolving the links it follows
+	i := strings.LastIndex(path, string(os.PathSeparator))
+	if i <= 0 {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(realPath(path[:i]), path[i+1:])
+}
+
+// CopyFile copies a single file from src to dst, keeping its permissions
+// The kernel copies the data itself where supported (see copyContents)
+func CopyFile(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer srcFile.Close()
+
+	// Get source file info for permissions
+	srcInfo, err := srcFile.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat source file: %w", err)
+	}
+
+	// Create destination file
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, srcInfo.Mode())
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer dstFile.Close()
+
+	// Copy contents
+	if err := copyContents(dstFile, srcFile, srcInfo.Size()); err != nil {
+		return fmt.Errorf("failed to copy file contents: %w", err)
+	}
+
+	// The mode passed to OpenFile is reduced by the umask
+	if err := dstFile.Chmod(srcInfo.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+
+	return nil
+}
+
+// bufferedCopy copies src to dst through a pooled buffer
+func bufferedCopy(dst io.Writer, src io.Reader) error {
+	buf := bufferPool.Get().(*[]byte)
+	defer bufferPool.Put(buf)
+
+	// Hide ReaderFrom/WriterTo so the pooled buffer is actually used
+	_, err := io.CopyBuffer(struct{ io.Writer }{dst}, struct{ io.Reader }{src}, *buf)
+	return err
+}
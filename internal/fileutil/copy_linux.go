@@ -0,0 +1,59 @@
+//go:build linux
+
+package fileutil
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// maxCopyRange is the largest chunk passed to a single copy_file_range call
+const maxCopyRange = 1 << 30
+
+// copyContents copies size bytes of src into the empty file dst
+// A reflink (FICLONE) is tried first, then copy_file_range; filesystems and kernels
+// supporting neither fall back to a buffered copy
+func copyContents(dst, src *os.File, size int64) error {
+	if size == 0 {
+		return nil
+	}
+
+	if err := unix.IoctlFileClone(int(dst.Fd()), int(src.Fd())); err == nil {
+		return nil
+	}
+
+	var copied int64
+	for copied < size {
+		n, err := unix.CopyFileRange(int(src.Fd()), nil, int(dst.Fd()), nil, int(min(size-copied, maxCopyRange)), 0)
+		if err != nil {
+			if copied == 0 && fallbackErr(err) {
+				break
+			}
+			return err
+		}
+		if n == 0 {
+			// The file shrank while copying, or the filesystem reports no data (e.g. procfs)
+			break
+		}
+		copied += int64(n)
+	}
+	if copied > 0 {
+		return nil
+	}
+
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return bufferedCopy(dst, src)
+}
+
+// fallbackErr reports whether copy_file_range failed because it cannot be used for
+// these files, rather than because of an I/O error
+func fallbackErr(err error) bool {
+	return errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EXDEV) ||
+		errors.Is(err, unix.EINVAL) || errors.Is(err, unix.EOPNOTSUPP) ||
+		errors.Is(err, unix.EPERM)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package fileutil
+
+import "os"
+
+// copyContents copies src into the empty file dst through a pooled buffer
+func copyContents(dst, src *os.File, size int64) error {
+	if size == 0 {
+		return nil
+	}
+	return bufferedCopy(dst, src)
+}
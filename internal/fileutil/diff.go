@@ -0,0 +1,81 @@
+package fileutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// TreeDiff lists the files that differ between two directory trees
+type TreeDiff struct {
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+// Empty reports whether the trees are identical
+func (d *TreeDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// Manifest returns a map of relative file paths to content hashes
+// .git is excluded; a missing directory yields an empty manifest
+func Manifest(dir string) (map[string]string, error) {
+	manifest := make(map[string]string)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return manifest, nil
+	}
+
+	err := walkFiles(dir, func(relPath string, info fs.FileInfo) error {
+		hash := sha256.New()
+		fmt.Fprintf(hash, "%o\x00", info.Mode().Perm())
+		if err := writeContent(hash, filepath.Join(dir, relPath), info); err != nil {
+			return err
+		}
+		manifest[relPath] = hex.EncodeToString(hash.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// DiffTrees compares the files of two directory trees
+// Paths in the result are slash-separated and sorted
+func DiffTrees(oldDir, newDir string) (*TreeDiff, error) {
+	oldManifest, err := Manifest(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newManifest, err := Manifest(newDir)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &TreeDiff{}
+	for path, hash := range newManifest {
+		oldHash, ok := oldManifest[path]
+		if !ok {
+			diff.Added = append(diff.Added, path)
+		} else if oldHash != hash {
+			diff.Changed = append(diff.Changed, path)
+		}
+	}
+	for path := range oldManifest {
+		if _, ok := newManifest[path]; !ok {
+			diff.Removed = append(diff.Removed, path)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Removed)
+
+	return diff, nil
+}
@@ -1,149 +1,21 @@
 package fileutil
 
 import (
-	"archive/zip"
-	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"strings"
-)
-
-// shouldIgnore checks if a file or directory should be ignored based on patterns
-func shouldIgnore(name string, patterns []string) bool {
-	for _, pattern := range patterns {
-		// Exact match
-		if name == pattern {
-			return true
-		}
-
-		// Wildcard pattern (simple glob)
-		if strings.Contains(pattern, "*") {
-			matched, _ := filepath.Match(pattern, name)
-			if matched {
-				return true
-			}
-		}
-	}
-	return false
-}
-
-// loadIgnoreFile loads ignore patterns from a .lancherignore file if it exists
-func loadIgnoreFile(srcDir string) []string {
-	ignoreFile := filepath.Join(srcDir, ".lancherignore")
-	file, err := os.Open(ignoreFile)
-	if err != nil {
-		// File doesn't exist or can't be read, return empty slice
-		return []string{}
-	}
-	defer file.Close()
 
-	var patterns []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		// Skip empty lines and comments
-		if line != "" && !strings.HasPrefix(line, "#") {
-			patterns = append(patterns, line)
-		}
-	}
-
-	return patterns
-}
+	"github.com/lancher-dev/lancher/internal/ignore"
+)
 
 // CopyDir recursively copies a directory from src to dst
+// Paths matched by .lancherignore files (gitignore syntax, nested per directory) are skipped
 func CopyDir(src, dst string) error {
-	// Get source directory info
-	srcInfo, err := os.Stat(src)
-	if err != nil {
-		return fmt.Errorf("failed to stat source: %w", err)
-	}
-
-	if !srcInfo.IsDir() {
-		return fmt.Errorf("source is not a directory: %s", src)
-	}
-
-	// Load ignore patterns from .lancherignore file if it exists
-	ignorePatterns := loadIgnoreFile(src)
-
-	return copyDirWithIgnore(src, dst, src, ignorePatterns)
-}
-
-// copyDirWithIgnore is the internal function that handles recursive copying with ignore patterns
-func copyDirWithIgnore(src, dst, rootSrc string, ignorePatterns []string) error {
-	// Get source directory info
-	srcInfo, err := os.Stat(src)
-	if err != nil {
-		return fmt.Errorf("failed to stat source: %w", err)
-	}
-
-	// Create destination directory
-	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
-		return fmt.Errorf("failed to create destination: %w", err)
-	}
-
-	// Read source directory
-	entries, err := os.ReadDir(src)
-	if err != nil {
-		return fmt.Errorf("failed to read source directory: %w", err)
-	}
-
-	// Copy each entry
-	for _, entry := range entries {
-		name := entry.Name()
-
-		// Skip if should be ignored
-		if shouldIgnore(name, ignorePatterns) {
-			continue
-		}
-
-		srcPath := filepath.Join(src, name)
-		dstPath := filepath.Join(dst, name)
-
-		if entry.IsDir() {
-			// Recursively copy subdirectory
-			if err := copyDirWithIgnore(srcPath, dstPath, rootSrc, ignorePatterns); err != nil {
-				return err
-			}
-		} else {
-			// Copy file
-			if err := CopyFile(srcPath, dstPath); err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
-}
-
-// CopyFile copies a single file from src to dst
-func CopyFile(src, dst string) error {
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return fmt.Errorf("failed to open source file: %w", err)
-	}
-	defer srcFile.Close()
-
-	// Get source file info for permissions
-	srcInfo, err := srcFile.Stat()
-	if err != nil {
-		return fmt.Errorf("failed to stat source file: %w", err)
-	}
-
-	// Create destination file
-	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, srcInfo.Mode())
-	if err != nil {
-		return fmt.Errorf("failed to create destination file: %w", err)
-	}
-	defer dstFile.Close()
-
-	// Copy contents
-	if _, err := io.Copy(dstFile, srcFile); err != nil {
-		return fmt.Errorf("failed to copy file contents: %w", err)
-	}
-
-	return nil
+	return CopyTree(src, dst, ignore.Options{Files: []string{ignore.LancherIgnoreFile}})
 }
 
 // RemoveDir removes a directory and all its contents
@@ -154,71 +26,91 @@ func RemoveDir(path string) error {
 	return nil
 }
 
-// UnzipToDir extracts a ZIP file to the specified directory
-func UnzipToDir(zipPath, destDir string) error {
-	// Open ZIP file
-	reader, err := zip.OpenReader(zipPath)
-	if err != nil {
-		return fmt.Errorf("failed to open zip file: %w", err)
-	}
-	defer reader.Close()
+// HashDir returns a content hash of a directory tree ("sha256:<hex>")
+// The hash covers relative paths, file modes and contents; .git is excluded
+func HashDir(dir string) (string, error) {
+	hash := sha256.New()
 
-	// Create destination directory
-	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return fmt.Errorf("failed to create destination directory: %w", err)
+	err := walkFiles(dir, func(relPath string, info fs.FileInfo) error {
+		fmt.Fprintf(hash, "%s\x00%o\x00", relPath, info.Mode().Perm())
+		if err := writeContent(hash, filepath.Join(dir, relPath), info); err != nil {
+			return err
+		}
+		hash.Write([]byte{0})
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash directory: %w", err)
 	}
 
-	// Extract each file
-	for _, file := range reader.File {
-		// Construct destination path
-		destPath := filepath.Join(destDir, file.Name)
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
 
-		// Security check: prevent zip slip vulnerability
-		if !strings.HasPrefix(destPath, filepath.Clean(destDir)+string(os.PathSeparator)) {
-			return fmt.Errorf("illegal file path in zip: %s", file.Name)
+// walkFiles calls fn with the slash-separated path and info of every file and symlink
+// of dir, excluding .git; HashDir and Manifest share it so they cover the same files
+func walkFiles(dir string, fn func(relPath string, info fs.FileInfo) error) error {
+	return ignore.Walk(dir, ignore.Options{Exclude: []string{".git"}}, func(relPath string, entry fs.DirEntry) error {
+		if entry.IsDir() {
+			return nil
 		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(relPath), info)
+	})
+}
 
-		if file.FileInfo().IsDir() {
-			// Create directory
-			if err := os.MkdirAll(destPath, file.Mode()); err != nil {
-				return fmt.Errorf("failed to create directory: %w", err)
-			}
-		} else {
-			// Create parent directory if needed
-			if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-				return fmt.Errorf("failed to create parent directory: %w", err)
-			}
-
-			// Extract file
-			if err := extractZipFile(file, destPath); err != nil {
-				return fmt.Errorf("failed to extract file %s: %w", file.Name, err)
-			}
+// writeContent writes what is hashed for a file to w: its data, or the target of a symlink
+func writeContent(w io.Writer, path string, info fs.FileInfo) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
 		}
+		_, err = io.WriteString(w, target)
+		return err
 	}
 
-	return nil
-}
-
-// extractZipFile extracts a single file from ZIP
-func extractZipFile(file *zip.File, destPath string) error {
-	// Open file in ZIP
-	srcFile, err := file.Open()
+	file, err := os.Open(path)
 	if err != nil {
 		return err
 	}
-	defer srcFile.Close()
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
 
-	// Create destination file
-	dstFile, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.Mode())
+// DirSize returns the total size in bytes and the number of files of a directory tree
+// Symlinks inside the tree count as files of their own size and are not followed
+func DirSize(dir string) (int64, int, error) {
+	var size int64
+	var files int
+
+	// The directory itself may be a link (see storage.CreateLink)
+	root, err := filepath.EvalSymlinks(dir)
 	if err != nil {
-		return err
+		return 0, 0, fmt.Errorf("failed to measure directory: %w", err)
 	}
-	defer dstFile.Close()
 
-	// Copy contents
-	if _, err := io.Copy(dstFile, srcFile); err != nil {
-		return err
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		files++
+		return nil
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to measure directory: %w", err)
 	}
 
-	return nil
+	return size, files, nil
 }
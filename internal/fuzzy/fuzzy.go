@@ -0,0 +1,169 @@
+// Package fuzzy ranks strings by how well they match a typed query
+// A query matches when each of its words appears in the text as a subsequence, ignoring case;
+// matches at word starts and runs of consecutive characters score higher
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Scoring weights
+const (
+	scoreMatch       = 16 // Every matched character
+	bonusWordStart   = 8  // Character starting a word (after a separator or a case change)
+	bonusFirstChar   = 4  // Character starting the text
+	bonusConsecutive = 12 // Character directly following the previous match
+	penaltyGap       = 1  // Every character skipped between two matches
+)
+
+// Result describes how a query matched a text
+type Result struct {
+	Score     int
+	Positions []int // Indices of the matched runes in the text, in ascending order
+}
+
+// Match matches query against text
+// Every whitespace-separated word of the query must match; an empty query matches everything
+// with a zero score
+func Match(query, text string) (Result, bool) {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// Lowercasing changed the rune count; fall back to comparing runes one by one
+		lower = make([]rune, len(runes))
+		for i, r := range runes {
+			lower[i] = unicode.ToLower(r)
+		}
+	}
+
+	var result Result
+	seen := make(map[int]bool)
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		score, positions, ok := matchWord([]rune(word), runes, lower)
+		if !ok {
+			return Result{}, false
+		}
+		result.Score += score
+		for _, pos := range positions {
+			if !seen[pos] {
+				seen[pos] = true
+				result.Positions = append(result.Positions, pos)
+			}
+		}
+	}
+	sort.Ints(result.Positions)
+
+	return result, true
+}
+
+// matchWord finds the best scoring alignment of pattern in text
+// best[i][j] is the best score with pattern[i] matched at text[j]
+func matchWord(pattern, text, lower []rune) (int, []int, bool) {
+	m, n := len(pattern), len(text)
+	if m > n {
+		return 0, nil, false
+	}
+
+	const none = -1 << 30
+	best := make([][]int, m)
+	from := make([][]int, m)
+	for i := range best {
+		best[i] = make([]int, n)
+		from[i] = make([]int, n)
+		for j := range best[i] {
+			best[i][j] = none
+		}
+	}
+
+	for i := 0; i < m; i++ {
+		for j := i; j < n; j++ {
+			if lower[j] != pattern[i] {
+				continue
+			}
+			bonus := scoreMatch + charBonus(text, j)
+			if i == 0 {
+				best[i][j] = bonus
+				continue
+			}
+			for k := i - 1; k < j; k++ {
+				if best[i-1][k] == none {
+					continue
+				}
+				score := best[i-1][k] + bonus
+				if k == j-1 {
+					score += bonusConsecutive
+				} else {
+					score -= penaltyGap * (j - k - 1)
+				}
+				if score > best[i][j] {
+					best[i][j] = score
+					from[i][j] = k
+				}
+			}
+		}
+	}
+
+	// Pick the best end position and walk the alignment back
+	end := -1
+	for j := m - 1; j < n; j++ {
+		if best[m-1][j] != none && (end < 0 || best[m-1][j] > best[m-1][end]) {
+			end = j
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	positions := make([]int, m)
+	for i, j := m-1, end; i >= 0; i-- {
+		positions[i] = j
+		j = from[i][j]
+	}
+	return best[m-1][end], positions, true
+}
+
+// charBonus returns the bonus for matching the rune at index i of text
+func charBonus(text []rune, i int) int {
+	if i == 0 {
+		return bonusFirstChar + bonusWordStart
+	}
+	prev, cur := text[i-1], text[i]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) && (unicode.IsLetter(cur) || unicode.IsDigit(cur)) {
+		return bonusWordStart
+	}
+	if unicode.IsLower(prev) && unicode.IsUpper(cur) {
+		return bonusWordStart
+	}
+	return 0
+}
+
+// Ranked is a matched text with its index in the ranked slice
+type Ranked struct {
+	Index int
+	Result
+}
+
+// Rank matches query against texts and returns the matches, best first
+// Ties are broken by shorter text, then by original order; an empty query keeps the order
+func Rank(query string, texts []string) []Ranked {
+	var ranked []Ranked
+	for i, text := range texts {
+		if result, ok := Match(query, text); ok {
+			ranked = append(ranked, Ranked{Index: i, Result: result})
+		}
+	}
+	if strings.TrimSpace(query) == "" {
+		return ranked
+	}
+
+	sort.SliceStable(ranked, func(a, b int) bool {
+		if ranked[a].Score != ranked[b].Score {
+			return ranked[a].Score > ranked[b].Score
+		}
+		return utf8.RuneCountInString(texts[ranked[a].Index]) < utf8.RuneCountInString(texts[ranked[b].Index])
+	})
+	return ranked
+}
@@ -0,0 +1,134 @@
+// Package gitutil wraps the git commands used to fetch and inspect templates
+package gitutil
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// commitPattern matches full or abbreviated commit hashes
+var commitPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+// Clone clones url into dest with a depth of 1
+// If ref is set, that branch, tag or commit is checked out instead of the default branch
+func Clone(url, dest, ref string, w io.Writer) error {
+	if ref == "" {
+		return run("", w, "clone", "--depth", "1", url, dest)
+	}
+
+	if err := run("", w, "init", "--quiet", dest); err != nil {
+		return err
+	}
+	err := run(dest, w, "remote", "add", "origin", url)
+	if err == nil {
+		err = Checkout(dest, ref, w)
+	}
+	if err != nil {
+		// Do not leave an empty repository behind, like git clone does on failure
+		os.RemoveAll(dest)
+		return err
+	}
+	return nil
+}
+
+// Checkout fetches ref (branch, tag or commit) from origin and checks it out as a detached HEAD
+func Checkout(dir, ref string, w io.Writer) error {
+	err := run(dir, w, "fetch", "--depth", "1", "origin", ref)
+	if err != nil && commitPattern.MatchString(ref) {
+		// Some servers refuse to serve arbitrary commits; fetch full history instead
+		err = run(dir, w, "fetch", "--unshallow", "origin")
+		if err != nil {
+			err = run(dir, w, "fetch", "origin")
+		}
+		if err == nil {
+			return run(dir, w, "checkout", "--quiet", "--detach", ref)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return run(dir, w, "checkout", "--quiet", "--detach", "FETCH_HEAD")
+}
+
+// Pull pulls the latest changes of the checked out branch
+func Pull(dir string, w io.Writer) error {
+	return run(dir, w, "pull")
+}
+
+// IsRepository reports whether dir contains a .git directory
+func IsRepository(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// HeadCommit returns the commit hash checked out in dir
+func HeadCommit(dir string) (string, error) {
+	return output(dir, "rev-parse", "HEAD")
+}
+
+// CurrentBranch returns the branch checked out in dir, or "" for a detached HEAD
+func CurrentBranch(dir string) (string, error) {
+	branch, err := output(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if branch == "HEAD" {
+		return "", nil
+	}
+	return branch, nil
+}
+
+// RemoteURL returns the URL of the origin remote in dir
+func RemoteURL(dir string) (string, error) {
+	return output(dir, "remote", "get-url", "origin")
+}
+
+// RemoteTags returns the tags published by the origin remote of dir
+func RemoteTags(dir string) ([]string, error) {
+	out, err := output(dir, "ls-remote", "--tags", "--refs", "origin")
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for _, line := range strings.Split(out, "\n") {
+		_, ref, found := strings.Cut(line, "\t")
+		if found && strings.HasPrefix(ref, "refs/tags/") {
+			tags = append(tags, strings.TrimPrefix(ref, "refs/tags/"))
+		}
+	}
+	return tags, nil
+}
+
+// run runs a git command in dir (or the current directory if empty), sending its output to w
+func run(dir string, w io.Writer, args ...string) error {
+	gitArgs := args
+	if dir != "" {
+		gitArgs = append([]string{"-C", dir}, args...)
+	}
+	cmd := exec.Command("git", gitArgs...)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return nil
+}
+
+// output runs a git command in dir and returns its trimmed standard output
+func output(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,250 @@
+// Package ignore implements gitignore-compatible path filtering
+package ignore
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LancherIgnoreFile is the per-directory ignore file honoured by lancher
+const LancherIgnoreFile = ".lancherignore"
+
+// Matcher evaluates an ordered list of patterns; the last matching pattern wins
+type Matcher struct {
+	patterns []Pattern
+}
+
+// NewMatcher creates a matcher from root-level pattern lines
+func NewMatcher(lines []string) *Matcher {
+	m := &Matcher{}
+	m.Add("", lines)
+	return m
+}
+
+// Add appends the pattern lines defined in the directory base
+// base is relative to the matcher root; "" is the root itself
+func (m *Matcher) Add(base string, lines []string) {
+	base = filepath.ToSlash(base)
+	for _, line := range lines {
+		if p, ok := ParsePattern(line, base); ok {
+			m.patterns = append(m.patterns, p)
+		}
+	}
+}
+
+// Len returns the number of patterns in the matcher
+func (m *Matcher) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.patterns)
+}
+
+// Match reports whether relPath is ignored by the patterns
+// Only the path itself is checked; see Ignored to also honour excluded parents
+func (m *Matcher) Match(relPath string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+
+	relPath = filepath.ToSlash(relPath)
+	ignored := false
+	for _, p := range m.patterns {
+		if p.Match(relPath, isDir) {
+			ignored = !p.Negate
+		}
+	}
+	return ignored
+}
+
+// Ignored reports whether relPath or any of its parent directories is ignored
+// As in git, a file cannot be re-included if a parent directory is excluded
+func (m *Matcher) Ignored(relPath string, isDir bool) bool {
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	for i := 1; i < len(parts); i++ {
+		if m.Match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.Match(relPath, isDir)
+}
+
+// truncate drops patterns added after the matcher had n patterns
+func (m *Matcher) truncate(n int) {
+	m.patterns = m.patterns[:n]
+}
+
+// ReadFile reads pattern lines from an ignore file
+// A missing file yields no lines and no error
+func ReadFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return lines, scanner.Err()
+}
+
+// Options configures the rules applied by Walk
+type Options struct {
+	// Files lists ignore file names loaded from every directory (e.g. ".lancherignore")
+	// Rules in a nested file apply relative to the directory containing it
+	Files []string
+
+	// Patterns are root-level rules applied before any ignore file
+	Patterns []string
+
+	// Exclude are rules that always apply and cannot be re-included with "!"
+	Exclude []string
+
+	// GitIgnore also applies .gitignore files from every directory and
+	// .git/info/exclude from the root, as git would
+	GitIgnore bool
+
+	// Follow is called with each symlink to a directory; when it returns true the link is
+	// walked as a directory, so rules keep matching the paths inside it as they appear
+	// under root. A link back into a directory being walked is reported as a cycle
+	Follow func(relPath string) bool
+}
+
+// GitIgnoreFile is the per-directory ignore file used by git
+const GitIgnoreFile = ".gitignore"
+
+// WalkFunc is called by Walk for every path that is not ignored
+// relPath is relative to the walked root; returning filepath.SkipDir for a
+// directory skips its contents
+type WalkFunc func(relPath string, entry fs.DirEntry) error
+
+// Walk walks the tree rooted at root in lexical order, skipping ignored paths
+// The root itself is not passed to fn
+func Walk(root string, opts Options, fn WalkFunc) error {
+	w := &walker{
+		root:    root,
+		opts:    opts,
+		exclude: NewMatcher(opts.Exclude),
+		rules:   NewMatcher(opts.Patterns),
+		fn:      fn,
+	}
+	if opts.Follow != nil {
+		w.active = make(map[string]bool)
+	}
+
+	if opts.GitIgnore {
+		// .git/info/exclude has the lowest precedence of git's rule sources
+		lines, err := ReadFile(filepath.Join(root, ".git", "info", "exclude"))
+		if err != nil {
+			return err
+		}
+		w.rules.Add("", lines)
+
+		// Per-directory .gitignore files are read before other ignore files,
+		// so .lancherignore can still override them
+		w.opts.Files = append([]string{GitIgnoreFile}, opts.Files...)
+	}
+
+	return w.walkDir("")
+}
+
+// List returns the relative paths of all files under root that are not ignored
+func List(root string, opts Options) ([]string, error) {
+	files := []string{}
+	err := Walk(root, opts, func(relPath string, entry fs.DirEntry) error {
+		if !entry.IsDir() {
+			files = append(files, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// walker holds the state of a single Walk
+type walker struct {
+	root    string
+	opts    Options
+	exclude *Matcher
+	rules   *Matcher
+	fn      WalkFunc
+	active  map[string]bool // Real paths of the directories being walked, when links are followed
+}
+
+// walkDir walks the directory rel (relative to root) with the rules collected so far
+func (w *walker) walkDir(rel string) error {
+	root, m, opts, fn := w.root, w.rules, w.opts, w.fn
+	dir := filepath.Join(root, rel)
+
+	if w.active != nil {
+		real, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			return err
+		}
+		if w.active[real] {
+			return fmt.Errorf("symlink cycle at %s", dir)
+		}
+		w.active[real] = true
+		defer delete(w.active, real)
+	}
+
+	// Load nested ignore files for this directory
+	n := m.Len()
+	defer m.truncate(n)
+	for _, name := range opts.Files {
+		lines, err := ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		m.Add(rel, lines)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		entryRel := filepath.Join(rel, entry.Name())
+		if entry.Type()&fs.ModeSymlink != 0 && opts.Follow != nil {
+			if info, err := os.Stat(filepath.Join(dir, entry.Name())); err == nil && info.IsDir() && opts.Follow(entryRel) {
+				entry = fs.FileInfoToDirEntry(info)
+			}
+		}
+		if w.exclude.Match(entryRel, entry.IsDir()) || m.Match(entryRel, entry.IsDir()) {
+			continue
+		}
+
+		err := fn(entryRel, entry)
+		if entry.IsDir() {
+			if err == filepath.SkipDir {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := w.walkDir(entryRel); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
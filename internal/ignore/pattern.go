@@ -0,0 +1,158 @@
+package ignore
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Pattern is a single gitignore-style rule
+type Pattern struct {
+	Raw      string // Original line
+	Base     string // Directory (slash-separated, relative to the root) the rule was defined in
+	Negate   bool   // Rule starts with "!" and re-includes matching paths
+	DirOnly  bool   // Rule ends with "/" and only matches directories
+	Anchored bool   // Rule contains a "/" and is matched relative to Base
+	re       *regexp.Regexp
+}
+
+// ParsePattern parses a single line of an ignore file defined in base
+// Returns false for blank lines, comments and invalid patterns
+func ParsePattern(line, base string) (Pattern, bool) {
+	line = trimTrailingSpaces(strings.TrimSuffix(line, "\r"))
+	if line == "" || strings.HasPrefix(line, "#") {
+		return Pattern{}, false
+	}
+
+	p := Pattern{Raw: line, Base: strings.Trim(base, "/")}
+
+	if strings.HasPrefix(line, "!") {
+		p.Negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		p.DirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	// A slash at the beginning or in the middle anchors the pattern to its base
+	if strings.Contains(line, "/") {
+		p.Anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	if line == "" {
+		return Pattern{}, false
+	}
+
+	expr := "^"
+	if !p.Anchored {
+		expr += "(?:.*/)?"
+	}
+	expr += globToRegexp(line) + "$"
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return Pattern{}, false
+	}
+	p.re = re
+
+	return p, true
+}
+
+// Match reports whether the pattern matches relPath (slash-separated, relative to the root)
+// Negation is not applied; callers decide what a match means
+func (p Pattern) Match(relPath string, isDir bool) bool {
+	if p.DirOnly && !isDir {
+		return false
+	}
+
+	if p.Base != "" {
+		if !strings.HasPrefix(relPath, p.Base+"/") {
+			return false
+		}
+		relPath = strings.TrimPrefix(relPath, p.Base+"/")
+	}
+
+	return p.re.MatchString(relPath)
+}
+
+// globToRegexp converts a slash-separated glob to a regular expression
+// Supports *, ?, [...] classes, backslash escapes and ** path segments
+func globToRegexp(glob string) string {
+	segments := strings.Split(glob, "/")
+
+	var b strings.Builder
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		if segment == "**" {
+			if last {
+				// Trailing "/**" matches everything inside
+				b.WriteString(".*")
+			} else {
+				// Leading "**/" and inner "/**/" match zero or more directories
+				b.WriteString("(?:.*/)?")
+			}
+			continue
+		}
+
+		b.WriteString(segmentToRegexp(segment))
+		if !last {
+			b.WriteString("/")
+		}
+	}
+
+	return b.String()
+}
+
+// segmentToRegexp converts a glob without slashes to a regular expression
+func segmentToRegexp(segment string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+		switch c {
+		case '*':
+			// Consecutive asterisks within a segment behave like a single one
+			for i+1 < len(segment) && segment[i+1] == '*' {
+				i++
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '\\':
+			if i+1 < len(segment) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(segment[i])))
+			} else {
+				b.WriteString(regexp.QuoteMeta("\\"))
+			}
+		case '[':
+			end := strings.IndexByte(segment[i+1:], ']')
+			if end < 0 {
+				b.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := segment[i+1 : i+1+end]
+			i += end + 1
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return b.String()
+}
+
+// trimTrailingSpaces removes trailing spaces unless they are escaped with a backslash
+func trimTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
@@ -0,0 +1,136 @@
+// Package registry keeps a metadata record for every stored template
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lancher-dev/lancher/internal/fileutil"
+)
+
+// Dir is the hidden directory holding metadata records inside a templates root
+const Dir = ".registry"
+
+// Source types
+const (
+	SourceLocal   = "local"
+	SourceLink    = "link" // Local directory used in place (see storage.CreateLink)
+	SourceArchive = "archive"
+	SourceGit     = "git"
+	SourceGitHub  = "gh"
+	SourceGitLab  = "gl"
+)
+
+// Entry is the metadata record of a stored template
+type Entry struct {
+	Name             string    `json:"name"`
+	SourceType       string    `json:"source_type"`
+	Source           string    `json:"source"`
+	Ref              string    `json:"ref,omitempty"`
+	Subdir           string    `json:"subdir,omitempty"` // Repository subdirectory holding the template
+	Commit           string    `json:"commit,omitempty"`
+	SHA256           string    `json:"sha256,omitempty"` // Pinned checksum of an archive URL
+	AddedAt          time.Time `json:"added_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	Hash             string    `json:"hash,omitempty"`
+	RespectGitignore bool      `json:"respect_gitignore,omitempty"`
+	StripTopLevel    bool      `json:"strip_top_level,omitempty"` // Archive extracted with its single top-level directory stripped
+	Symlinks         string    `json:"symlinks,omitempty"`        // Symlink policy used when copying a local directory
+}
+
+// IsGit reports whether the entry was cloned from a git repository
+func (e *Entry) IsGit() bool {
+	return e != nil && (e.SourceType == SourceGit || e.SourceType == SourceGitHub || e.SourceType == SourceGitLab)
+}
+
+// IsArchive reports whether the entry was extracted from an archive file
+func (e *Entry) IsArchive() bool {
+	return e != nil && e.SourceType == SourceArchive
+}
+
+// ExtractOptions returns the options used to extract the archive of the entry
+func (e *Entry) ExtractOptions() fileutil.ExtractOptions {
+	return fileutil.ExtractOptions{StripTopLevel: e.StripTopLevel}
+}
+
+// Location returns the source including its repository subdirectory, if any
+func (e *Entry) Location() string {
+	if e.Subdir != "" {
+		return e.Source + "//" + e.Subdir
+	}
+	return e.Source
+}
+
+// ShortCommit returns the abbreviated commit hash
+func (e *Entry) ShortCommit() string {
+	if e == nil {
+		return ""
+	}
+	if len(e.Commit) > 7 {
+		return e.Commit[:7]
+	}
+	return e.Commit
+}
+
+// entryPath returns the metadata file of a template in the templates root
+func entryPath(root, name string) string {
+	return filepath.Join(root, Dir, filepath.FromSlash(name)+".json")
+}
+
+// Load reads the metadata record of a template in the templates root
+// Returns nil without error if no record exists
+func Load(root, name string) (*Entry, error) {
+	data, err := os.ReadFile(entryPath(root, name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata for '%s': %w", name, err)
+	}
+	return &entry, nil
+}
+
+// Save writes the metadata record of a template in the templates root
+func Save(root string, entry *Entry) error {
+	path := entryPath(root, entry.Name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create registry directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a failed write never leaves a truncated record
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the metadata record of a template in the templates root
+func Remove(root, name string) error {
+	path := entryPath(root, name)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove metadata: %w", err)
+	}
+	// Drop the namespace directory of namespaced names once empty
+	if dir := filepath.Dir(path); dir != filepath.Join(root, Dir) {
+		os.Remove(dir)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package sources
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lancher-dev/lancher/internal/fileutil"
+)
+
+// downloadTimeout bounds a whole archive download
+const downloadTimeout = 5 * time.Minute
+
+// probeTimeout bounds the HEAD request made by ProbeArchiveURL
+const probeTimeout = 10 * time.Second
+
+// archiveContentTypes are the Content-Type values of supported archive formats
+var archiveContentTypes = []string{
+	"application/zip", "application/x-zip-compressed",
+	"application/gzip", "application/x-gzip", "application/x-compressed-tar",
+	"application/x-tar", "application/x-gtar",
+	"application/x-xz", "application/zstd", "application/x-bzip2",
+}
+
+// IsHTTPURL checks if source is an HTTP(S) link
+func IsHTTPURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// IsArchiveURL checks if source is an HTTP(S) link to an archive file, from its extension
+// See DetectArchiveURL and ProbeArchiveURL for links without one
+func IsArchiveURL(source string) bool {
+	if !IsHTTPURL(source) {
+		return false
+	}
+	u, err := url.Parse(source)
+	if err != nil {
+		return false
+	}
+	return hasArchiveExtension(u.Path)
+}
+
+// gitHosts serve git repositories over HTTPS; links to them are never probed
+var gitHosts = []string{"github.com", "gitlab.com", "bitbucket.org", "codeberg.org"}
+
+// DetectArchiveURL checks, without a request, if source is an HTTP(S) link to an archive
+// Links with an archive extension are archives (see IsArchiveURL), as are links given
+// with a checksum. Any other link is cloned with git first; see ProbeArchiveURL
+func DetectArchiveURL(source, checksum string) bool {
+	return IsArchiveURL(source) || (IsHTTPURL(source) && checksum != "")
+}
+
+// ProbeArchiveURL checks if an HTTP(S) link without an archive extension, as served by
+// artifact servers or codeload, is an archive from the Content-Type of a HEAD request
+// Links ending in .git and links to known git hosts are git URLs and are not requested
+func ProbeArchiveURL(source string) bool {
+	if !IsHTTPURL(source) {
+		return false
+	}
+	u, err := url.Parse(source)
+	if err != nil || strings.HasSuffix(strings.TrimSuffix(u.Path, "/"), ".git") || isGitHost(u.Hostname()) {
+		return false
+	}
+
+	client := &http.Client{Timeout: probeTimeout}
+	req, err := http.NewRequest("HEAD", source, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("User-Agent", "lancher")
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	contentType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	for _, archiveType := range archiveContentTypes {
+		if contentType == archiveType {
+			return true
+		}
+	}
+	return false
+}
+
+// isGitHost checks if host is one of gitHosts
+func isGitHost(host string) bool {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	for _, gitHost := range gitHosts {
+		if host == gitHost {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeSHA256 validates a SHA-256 checksum and returns it as lowercase hex
+// An optional "sha256:" prefix is accepted
+func NormalizeSHA256(checksum string) (string, error) {
+	sum := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(checksum), "sha256:"))
+	if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid SHA-256 checksum '%s'", checksum)
+	}
+	return sum, nil
+}
+
+// Download saves the file at url to dest
+// If checksum is set the download is verified against it and removed on mismatch
+// A download larger than maxSize bytes is aborted and removed
+// Returns the SHA-256 checksum of the downloaded file
+func Download(url, dest, checksum string, maxSize int64) (string, error) {
+	client := &http.Client{
+		Timeout: downloadTimeout,
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "lancher")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: status %d", url, resp.StatusCode)
+	}
+	sizeErr := fmt.Errorf("failed to download %s: file exceeds the maximum size of %d bytes", url, maxSize)
+	if resp.ContentLength > maxSize {
+		return "", sizeErr
+	}
+
+	file, err := os.Create(dest)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	// One byte past the limit tells an oversized body from one of exactly maxSize bytes
+	n, err := io.Copy(io.MultiWriter(file, hash), io.LimitReader(resp.Body, maxSize+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dest)
+		return "", fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	if n > maxSize {
+		os.Remove(dest)
+		return "", sizeErr
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if checksum != "" && sum != checksum {
+		os.Remove(dest)
+		return "", fmt.Errorf("checksum mismatch for %s\n  expected: %s\n  got:      %s", url, checksum, sum)
+	}
+	return sum, nil
+}
+
+// FetchArchive downloads an archive URL, verifies it against checksum if set and
+// extracts it into dest
+// The download is bounded by the extraction size limit of opts
+// Returns the SHA-256 checksum of the archive
+func FetchArchive(url, dest, checksum string, opts fileutil.ExtractOptions) (string, error) {
+	tmp, err := os.MkdirTemp("", "lancher-download-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	archive := filepath.Join(tmp, "archive")
+	sum, err := Download(url, archive, checksum, opts.SizeLimit())
+	if err != nil {
+		return "", err
+	}
+	if err := fileutil.ExtractArchive(archive, dest, opts); err != nil {
+		return "", fmt.Errorf("failed to extract archive: %w", err)
+	}
+	return sum, nil
+}
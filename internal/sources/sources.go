@@ -0,0 +1,309 @@
+// Package sources recognizes template sources (local paths, archives, archive URLs,
+// git URLs and gh:/gl: aliases) and fetches remote ones
+package sources
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/lancher-dev/lancher/internal/fileutil"
+	"github.com/lancher-dev/lancher/internal/gitutil"
+	"github.com/lancher-dev/lancher/internal/ignore"
+)
+
+// Base URLs used when an alias is cloned without the platform CLI
+const (
+	GitHubURL = "https://github.com/"
+	GitLabURL = "https://gitlab.com/"
+)
+
+// IsGitURL checks if source is a git URL
+// HTTP(S) links to archive files are not git URLs
+func IsGitURL(source string) bool {
+	if IsArchiveURL(source) {
+		return false
+	}
+	return strings.HasPrefix(source, "http://") ||
+		strings.HasPrefix(source, "https://") ||
+		strings.HasPrefix(source, "file://") ||
+		strings.HasPrefix(source, "git@") ||
+		strings.HasSuffix(source, ".git")
+}
+
+// IsGitHubAlias checks if source uses gh: alias
+func IsGitHubAlias(source string) bool {
+	return strings.HasPrefix(source, "gh:")
+}
+
+// IsGitLabAlias checks if source uses gl: alias
+func IsGitLabAlias(source string) bool {
+	return strings.HasPrefix(source, "gl:")
+}
+
+// IsRemote checks if source is a git URL or a gh:/gl: alias
+func IsRemote(source string) bool {
+	return IsGitURL(source) || IsGitHubAlias(source) || IsGitLabAlias(source)
+}
+
+// archiveExtensions are the file extensions of supported archive formats
+var archiveExtensions = []string{".zip", ".tar", ".tar.gz", ".tgz", ".tar.xz", ".txz", ".tar.zst", ".tzst", ".tar.bz2", ".tbz2"}
+
+// IsArchive checks if source is an archive file, detected from its contents
+// A missing file is recognized by its extension so the caller can report it as such
+func IsArchive(source string) bool {
+	if _, err := os.Stat(source); os.IsNotExist(err) {
+		return hasArchiveExtension(source)
+	}
+	return fileutil.IsArchive(source)
+}
+
+// hasArchiveExtension checks if source ends with the extension of a supported archive format
+func hasArchiveExtension(source string) bool {
+	lower := strings.ToLower(source)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPath checks if source is an explicit filesystem path (starting with ./, ../, / or ~)
+// Bare names are not paths, so they can be told apart from template names
+func IsPath(source string) bool {
+	if source == "." || source == ".." || source == "~" {
+		return true
+	}
+	for _, prefix := range []string{"./", "../", "/", "~/"} {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	return filepath.IsAbs(source)
+}
+
+// IsSource checks if value refers to a source rather than a stored template name
+// Remote sources may carry a trailing "@ref"
+func IsSource(value string) bool {
+	source, _ := SplitRef(value)
+	return IsPath(value) || IsArchiveURL(value) || IsRemote(source)
+}
+
+// EnsureGitSuffix adds .git suffix if not present
+func EnsureGitSuffix(url string) string {
+	if !strings.HasSuffix(url, ".git") {
+		return url + ".git"
+	}
+	return url
+}
+
+// SplitRef splits a trailing "@ref" from a remote source (e.g. "gh:org/repo@v1.2.0")
+// Refs may contain "/" (e.g. "gh:org/repo@feature/x"); a source with a "//subdir"
+// suffix must be split with SplitSubdir first, see SplitRemote
+// The "@" in scp-like URLs such as git@host:org/repo.git and the user info of URLs
+// such as https://user@host/org/repo are not treated as a ref
+func SplitRef(source string) (string, string) {
+	i := strings.LastIndex(source, "@")
+	if i <= 0 || strings.Contains(source[i+1:], ":") {
+		return source, ""
+	}
+	if j := strings.Index(source, "://"); j >= 0 && !strings.Contains(source[j+len("://"):i], "/") {
+		return source, ""
+	}
+	return source[:i], source[i+1:]
+}
+
+// SplitRemote splits a remote source into its repository, ref and subdirectory
+// Both "repo//subdir@ref" and "repo@ref//subdir" are accepted
+func SplitRemote(source string) (repo, ref, subdir string) {
+	repo, subdir = SplitSubdir(source)
+	if repo, ref = SplitRef(repo); ref == "" {
+		subdir, ref = SplitRef(subdir)
+	}
+	return repo, ref, subdir
+}
+
+// SplitSubdir splits a "//subdir" suffix from a remote source (e.g. "gh:org/templates//services/go")
+// The "//" following a URL scheme is not treated as a separator
+func SplitSubdir(source string) (string, string) {
+	start := 0
+	if i := strings.Index(source, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	i := strings.Index(source[start:], "//")
+	if i < 0 {
+		return source, ""
+	}
+	return source[:start+i], strings.Trim(source[start+i+2:], "/")
+}
+
+// CleanSubdir validates a repository subdirectory and returns it in slash-separated form
+// Absolute paths and paths leaving the repository are rejected
+func CleanSubdir(subdir string) (string, error) {
+	slashed := filepath.ToSlash(subdir)
+	for _, segment := range strings.Split(slashed, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("invalid subdirectory '%s'", subdir)
+		}
+	}
+	cleaned := strings.TrimPrefix(path.Clean("/"+slashed), "/")
+	if cleaned == "" || strings.HasPrefix(slashed, "/") {
+		return "", fmt.Errorf("invalid subdirectory '%s'", subdir)
+	}
+	return cleaned, nil
+}
+
+// CloneSubdir clones source at ref into a temporary directory and copies one of its
+// subdirectories into dest
+// Returns the cloned commit
+func CloneSubdir(source, subdir, dest, ref string, w io.Writer) (string, error) {
+	tmp, err := os.MkdirTemp("", "lancher-clone-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	clone := filepath.Join(tmp, "repo")
+	if _, err := Clone(source, clone, ref, w); err != nil {
+		return "", err
+	}
+	commit, err := gitutil.HeadCommit(clone)
+	if err != nil {
+		return "", err
+	}
+
+	src := filepath.Join(clone, filepath.FromSlash(subdir))
+	if info, err := os.Stat(src); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("subdirectory '%s' not found in repository", subdir)
+	}
+	if err := fileutil.CopyTree(src, dest, ignore.Options{Exclude: []string{".git"}}); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// aliasTarget returns the CLI command, base URL and repository path of a gh:/gl: alias
+func aliasTarget(source string) (string, string, string) {
+	if IsGitHubAlias(source) {
+		return "gh", GitHubURL, strings.TrimPrefix(source, "gh:")
+	}
+	return "glab", GitLabURL, strings.TrimPrefix(source, "gl:")
+}
+
+// UsesCLI reports whether cloning source goes through the GitHub or GitLab CLI
+func UsesCLI(source string) bool {
+	if !IsGitHubAlias(source) && !IsGitLabAlias(source) {
+		return false
+	}
+	cliCmd, _, _ := aliasTarget(source)
+	_, err := exec.LookPath(cliCmd)
+	return err == nil
+}
+
+// Clone clones a git URL or gh:/gl: alias into dest, checking out ref if set
+// Aliases go through the platform CLI when installed and fall back to an HTTPS clone
+// Returns the form of the source that was cloned, for display
+func Clone(source, dest, ref string, w io.Writer) (string, error) {
+	if !IsGitHubAlias(source) && !IsGitLabAlias(source) {
+		return source, gitutil.Clone(source, dest, ref, w)
+	}
+
+	cliCmd, baseURL, repoPath := aliasTarget(source)
+	if !UsesCLI(source) {
+		gitURL := EnsureGitSuffix(baseURL + repoPath)
+		return gitURL, gitutil.Clone(gitURL, dest, ref, w)
+	}
+
+	// Use CLI tool (gh repo clone or glab repo clone)
+	cloneArgs := []string{"repo", "clone", repoPath, dest, "--", "--depth", "1"}
+	if ref != "" {
+		// The pinned ref is fetched and checked out after the clone
+		cloneArgs = append(cloneArgs, "--no-checkout")
+	}
+	cmd := exec.Command(cliCmd, cloneArgs...)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	display := fmt.Sprintf("%s:%s", cliCmd, repoPath)
+	if err := cmd.Run(); err != nil {
+		return display, err
+	}
+	if ref != "" {
+		if err := gitutil.Checkout(dest, ref, w); err != nil {
+			os.RemoveAll(dest)
+			return display, err
+		}
+	}
+	return display, nil
+}
+
+// CacheDir returns the directory holding fetched sources
+// ($XDG_CACHE_HOME/lancher/sources or ~/.cache/lancher/sources)
+func CacheDir() (string, error) {
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	if cacheHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		cacheHome = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(cacheHome, "lancher", "sources"), nil
+}
+
+// Fetch returns an up-to-date copy of a remote source at ref in the cache directory
+// A cached copy is refreshed in place; otherwise the source is cloned
+func Fetch(source, ref string, w io.Writer) (string, error) {
+	cacheDir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(source + "@" + ref))
+	dir := filepath.Join(cacheDir, hex.EncodeToString(sum[:8]))
+
+	if gitutil.IsRepository(dir) {
+		if ref != "" {
+			err = gitutil.Checkout(dir, ref, w)
+		} else {
+			err = gitutil.Pull(dir, w)
+		}
+		if err == nil {
+			return dir, nil
+		}
+		// A broken cache entry is replaced by a fresh clone
+		if err := os.RemoveAll(dir); err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Clone next to the final location so an interrupted clone is never used
+	tmp, err := os.MkdirTemp(cacheDir, ".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	clone := filepath.Join(tmp, "clone")
+	if _, err := Clone(source, clone, ref, w); err != nil {
+		return "", err
+	}
+	// Servers answering every path, such as artifact links with a query, clone as empty repositories
+	if _, err := gitutil.HeadCommit(clone); err != nil {
+		return "", fmt.Errorf("repository has no commits")
+	}
+	if err := os.Rename(clone, dir); err != nil {
+		return "", fmt.Errorf("failed to store fetched source: %w", err)
+	}
+	return dir, nil
+}
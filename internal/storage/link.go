@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// CreateLink registers the directory target as the linked template name
+// The template path is a symlink, so the template is always read from the live directory
+// Returns the template path
+func CreateLink(name, target string) (string, error) {
+	path, err := GetTemplatePath(name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	return path, os.Symlink(target, path)
+}
+
+// LinkTarget returns the directory a linked template points to
+// The boolean result is false for templates stored as a copy
+func LinkTarget(path string) (string, bool) {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return "", false
+	}
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", false
+	}
+	return target, true
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NamespaceMarker marks a directory in a root as a namespace holding templates (e.g. "platform/go-service")
+const NamespaceMarker = ".lancher-namespace"
+
+// SplitName splits a template name into its namespace and base name
+// The namespace is empty for names without one
+func SplitName(name string) (string, string) {
+	if namespace, base, found := strings.Cut(name, "/"); found {
+		return namespace, base
+	}
+	return "", name
+}
+
+// IsNamespace reports whether dir is a namespace directory
+func IsNamespace(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, NamespaceMarker))
+	return err == nil
+}
+
+// CreateNamespace prepares the user templates directory for storing a template named name
+// The namespace directory and its marker are created for namespaced names; an error is
+// returned if name clashes with an existing namespace or template
+func CreateNamespace(name string) error {
+	templatesDir, err := GetTemplatesDir()
+	if err != nil {
+		return err
+	}
+
+	namespace, _ := SplitName(name)
+	if namespace == "" {
+		if IsNamespace(filepath.Join(templatesDir, name)) {
+			return fmt.Errorf("'%s' is a namespace", name)
+		}
+		return nil
+	}
+
+	dir := filepath.Join(templatesDir, namespace)
+	if isDir(dir) && !IsNamespace(dir) {
+		return fmt.Errorf("'%s' is a template, not a namespace", namespace)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create namespace: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, NamespaceMarker), nil, 0644)
+}
+
+// RemoveEmptyNamespace deletes the namespace directory of name from the user templates directory
+// once it no longer holds any template
+func RemoveEmptyNamespace(name string) error {
+	namespace, _ := SplitName(name)
+	if namespace == "" {
+		return nil
+	}
+
+	templatesDir, err := GetTemplatesDir()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(templatesDir, namespace)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() != NamespaceMarker {
+			return nil
+		}
+	}
+	return os.RemoveAll(dir)
+}
+
+// templateDir returns the directory of the template name in root, or "" if root does not hold it
+// Namespaces are not templates, and a namespaced name requires its namespace marker
+func templateDir(root, name string) string {
+	path := filepath.Join(root, filepath.FromSlash(name))
+	// A linked template counts even when its target is missing, so it can be reported and removed
+	if _, linked := LinkTarget(path); !linked && (!isDir(path) || IsNamespace(path)) {
+		return ""
+	}
+	if namespace, _ := SplitName(name); namespace != "" && !IsNamespace(filepath.Join(root, namespace)) {
+		return ""
+	}
+	return path
+}
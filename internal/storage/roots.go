@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Scope identifies which kind of root a template was found in
+type Scope string
+
+const (
+	// ScopeProject is a .lancher/templates directory found above the working directory
+	ScopeProject Scope = "project"
+	// ScopeUser is the user templates directory (see GetTemplatesDir)
+	ScopeUser Scope = "user"
+	// ScopeSystem is the system-wide templates directory
+	ScopeSystem Scope = "system"
+)
+
+// ProjectTemplatesDir is the project-local templates directory, relative to a project root
+var ProjectTemplatesDir = filepath.Join(".lancher", "templates")
+
+// SystemTemplatesDir is the system-wide templates directory
+const SystemTemplatesDir = "/usr/share/lancher/templates"
+
+// Root is a directory searched for templates
+type Root struct {
+	Path  string
+	Scope Scope
+}
+
+// Template describes a template found in one of the roots
+type Template struct {
+	Name     string
+	Path     string
+	Root     Root
+	Shadowed bool // A template with the same name exists in a higher-priority root
+	Linked   bool // The template is a link to a directory outside the root (see CreateLink)
+}
+
+// QualifiedName returns a name identifying the template across roots
+// Templates of the user root keep their name; others are prefixed with their scope and a
+// digest of their root, so equal names in different roots never collide. The '@' prefix
+// cannot appear in a template name
+func (t Template) QualifiedName() string {
+	if t.Root.Scope == ScopeUser {
+		return t.Name
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(t.Root.Path)))
+	return fmt.Sprintf("@%s-%x/%s", t.Root.Scope, sum[:4], t.Name)
+}
+
+// GetRoots returns the template roots in priority order:
+// project-local, user, then system
+// The user root is always included; the others only when they exist
+func GetRoots() ([]Root, error) {
+	var roots []Root
+
+	cwd, err := os.Getwd()
+	if err == nil {
+		if dir := findProjectTemplatesDir(cwd); dir != "" {
+			roots = append(roots, Root{Path: dir, Scope: ScopeProject})
+		}
+	}
+
+	userDir, err := GetTemplatesDir()
+	if err != nil {
+		return nil, err
+	}
+	roots = appendRoot(roots, Root{Path: userDir, Scope: ScopeUser})
+
+	if isDir(SystemTemplatesDir) {
+		roots = appendRoot(roots, Root{Path: SystemTemplatesDir, Scope: ScopeSystem})
+	}
+
+	return roots, nil
+}
+
+// FindTemplate returns the highest-priority template with the given name
+// The boolean result is false if no root contains the template
+func FindTemplate(name string) (Template, bool, error) {
+	roots, err := GetRoots()
+	if err != nil {
+		return Template{}, false, err
+	}
+
+	for _, root := range roots {
+		if path := templateDir(root.Path, name); path != "" {
+			return Template{Name: name, Path: path, Root: root}, true, nil
+		}
+	}
+
+	return Template{}, false, nil
+}
+
+// findProjectTemplatesDir walks up from dir looking for a project-local templates directory
+func findProjectTemplatesDir(dir string) string {
+	for {
+		candidate := filepath.Join(dir, ProjectTemplatesDir)
+		if isDir(candidate) {
+			return candidate
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// appendRoot appends root unless a root with the same path is already present
+func appendRoot(roots []Root, root Root) []Root {
+	for _, existing := range roots {
+		if filepath.Clean(existing.Path) == filepath.Clean(root.Path) {
+			return roots
+		}
+	}
+	return append(roots, root)
+}
+
+// isDir reports whether path exists and is a directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -4,12 +4,74 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/lancher-dev/lancher/internal/config"
+)
+
+// Environment variables overriding the templates directory
+const (
+	EnvTemplatesDir = "LANCHER_TEMPLATES_DIR"
+	EnvHome         = "LANCHER_HOME"
 )
 
-// GetTemplatesDir returns the platform-specific templates directory
+// templatesDirOverride is set by the global --templates-dir flag
+var templatesDirOverride string
+
+// SetTemplatesDir overrides the templates directory for the current process
+// An empty value restores the default resolution
+func SetTemplatesDir(dir string) error {
+	if dir == "" {
+		templatesDirOverride = ""
+		return nil
+	}
+	abs, err := filepath.Abs(config.ExpandPath(dir, ""))
+	if err != nil {
+		return err
+	}
+	templatesDirOverride = abs
+	return nil
+}
+
+// GetTemplatesDir returns the templates directory
+// Resolution order: --templates-dir flag, LANCHER_TEMPLATES_DIR,
+// LANCHER_HOME/templates, templates_dir from the user config, platform default
 func GetTemplatesDir() (string, error) {
-	var baseDir string
+	baseDir, err := resolveTemplatesDir()
+	if err != nil {
+		return "", err
+	}
+
+	// Ensure directory exists
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return "", err
+	}
+
+	return baseDir, nil
+}
+
+// resolveTemplatesDir determines the templates directory without creating it
+func resolveTemplatesDir() (string, error) {
+	if templatesDirOverride != "" {
+		return templatesDirOverride, nil
+	}
+	if dir := os.Getenv(EnvTemplatesDir); dir != "" {
+		return filepath.Abs(config.ExpandPath(dir, ""))
+	}
+	if home := os.Getenv(EnvHome); home != "" {
+		return filepath.Abs(filepath.Join(config.ExpandPath(home, ""), "templates"))
+	}
+
+	userCfg, err := config.LoadUserConfig()
+	if err != nil {
+		return "", err
+	}
+	if userCfg.TemplatesDir != "" {
+		return userCfg.TemplatesDir, nil
+	}
 
+	var baseDir string
 	if runtime.GOOS == "darwin" {
 		// macOS: ~/Library/Application Support/lancher/templates
 		home, err := os.UserHomeDir()
@@ -30,11 +92,6 @@ func GetTemplatesDir() (string, error) {
 		baseDir = filepath.Join(dataHome, "lancher", "templates")
 	}
 
-	// Ensure directory exists
-	if err := os.MkdirAll(baseDir, 0755); err != nil {
-		return "", err
-	}
-
 	return baseDir, nil
 }
 
@@ -44,35 +101,114 @@ func GetTemplatePath(name string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(templatesDir, name), nil
+	return filepath.Join(templatesDir, filepath.FromSlash(name)), nil
 }
 
 // TemplateExists checks if a template exists
 func TemplateExists(name string) (bool, error) {
-	path, err := GetTemplatePath(name)
+	templatesDir, err := GetTemplatesDir()
 	if err != nil {
 		return false, err
 	}
+	return templateDir(templatesDir, name) != "", nil
+}
 
-	info, err := os.Stat(path)
-	if os.IsNotExist(err) {
-		return false, nil
-	}
+// ListTemplates returns the names of all templates across all roots, sorted by name
+// A name shadowed by a higher-priority root is only returned once
+func ListTemplates() ([]string, error) {
+	all, err := ListAllTemplates()
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	templates := []string{}
+	for _, tmpl := range all {
+		if !tmpl.Shadowed {
+			templates = append(templates, tmpl.Name)
+		}
 	}
 
-	return info.IsDir(), nil
+	return templates, nil
 }
 
-// ListTemplates returns all template names
-func ListTemplates() ([]string, error) {
+// ListUserTemplates returns the names of the templates in the user templates directory,
+// sorted by name; only these can be changed by add, update, rollback and remove
+func ListUserTemplates() ([]string, error) {
 	templatesDir, err := GetTemplatesDir()
 	if err != nil {
 		return nil, err
 	}
+	return listRoot(templatesDir)
+}
+
+// ListAllTemplates returns every template found in every root, sorted by name
+// Templates with the same name are ordered by root priority; all but the first are marked as shadowed
+func ListAllTemplates() ([]Template, error) {
+	roots, err := GetRoots()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	templates := []Template{}
+	for _, root := range roots {
+		names, err := listRoot(root.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			path := filepath.Join(root.Path, filepath.FromSlash(name))
+			_, linked := LinkTarget(path)
+			templates = append(templates, Template{
+				Name:     name,
+				Path:     path,
+				Root:     root,
+				Shadowed: seen[name],
+				Linked:   linked,
+			})
+			seen[name] = true
+		}
+	}
+
+	// Stable sort keeps root priority order among templates with the same name
+	sort.SliceStable(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	return templates, nil
+}
+
+// listRoot returns the template names stored in a root directory
+// Templates inside namespace directories are returned as "namespace/name"
+func listRoot(dir string) ([]string, error) {
+	names, err := listDirs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []string{}
+	for _, name := range names {
+		if !IsNamespace(filepath.Join(dir, name)) {
+			templates = append(templates, name)
+			continue
+		}
+
+		nested, err := listDirs(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		for _, base := range nested {
+			templates = append(templates, name+"/"+base)
+		}
+	}
+
+	return templates, nil
+}
 
-	entries, err := os.ReadDir(templatesDir)
+// listDirs returns the names of the visible directories and links in dir
+// Links are included even when their target is missing, so linked templates can be reported
+func listDirs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []string{}, nil
@@ -80,12 +216,16 @@ func ListTemplates() ([]string, error) {
 		return nil, err
 	}
 
-	templates := []string{}
+	names := []string{}
 	for _, entry := range entries {
-		if entry.IsDir() {
-			templates = append(templates, entry.Name())
+		// Hidden directories hold lancher metadata, not templates
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if entry.IsDir() || entry.Type()&os.ModeSymlink != 0 {
+			names = append(names, entry.Name())
 		}
 	}
 
-	return templates, nil
+	return names, nil
 }
@@ -26,19 +26,24 @@ func GetFull() string {
 }
 
 // Compare compares two version strings in format v0.0.1
+// Parts are compared numerically, and a pre-release (v1.2.0-rc.1) comes before its
+// release; build metadata (+...) is ignored, as in semantic versioning
 // Returns:
 //
 //	 1 if v1 > v2
 //	 0 if v1 == v2
 //	-1 if v1 < v2
 func Compare(v1, v2 string) int {
-	// Remove 'v' prefix if present
-	v1 = strings.TrimPrefix(v1, "v")
-	v2 = strings.TrimPrefix(v2, "v")
+	// Remove 'v' prefix and build metadata if present
+	v1, _, _ = strings.Cut(strings.TrimPrefix(v1, "v"), "+")
+	v2, _, _ = strings.Cut(strings.TrimPrefix(v2, "v"), "+")
+
+	core1, pre1, _ := strings.Cut(v1, "-")
+	core2, pre2, _ := strings.Cut(v2, "-")
 
 	// Split by dots
-	parts1 := strings.Split(v1, ".")
-	parts2 := strings.Split(v2, ".")
+	parts1 := strings.Split(core1, ".")
+	parts2 := strings.Split(core2, ".")
 
 	// Compare each part
 	maxLen := len(parts1)
@@ -64,6 +69,53 @@ func Compare(v1, v2 string) int {
 		}
 	}
 
+	return comparePrerelease(pre1, pre2)
+}
+
+// comparePrerelease compares the pre-release parts of two versions with equal cores
+// A version without a pre-release is newer; identifiers are compared one by one,
+// numbers numerically and before text, and a longer list wins when all others are equal
+func comparePrerelease(pre1, pre2 string) int {
+	switch {
+	case pre1 == pre2:
+		return 0
+	case pre1 == "":
+		return 1
+	case pre2 == "":
+		return -1
+	}
+
+	ids1 := strings.Split(pre1, ".")
+	ids2 := strings.Split(pre2, ".")
+	for i := 0; i < len(ids1) && i < len(ids2); i++ {
+		n1, err1 := strconv.Atoi(ids1[i])
+		n2, err2 := strconv.Atoi(ids2[i])
+		switch {
+		case err1 == nil && err2 == nil:
+			if n1 != n2 {
+				return compareInts(n1, n2)
+			}
+		case err1 == nil:
+			return -1
+		case err2 == nil:
+			return 1
+		default:
+			if c := strings.Compare(ids1[i], ids2[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInts(len(ids1), len(ids2))
+}
+
+// compareInts returns 1, 0 or -1 as a is greater than, equal to or less than b
+func compareInts(a, b int) int {
+	switch {
+	case a > b:
+		return 1
+	case a < b:
+		return -1
+	}
 	return 0
 }
 
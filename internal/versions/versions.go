@@ -0,0 +1,287 @@
+// Package versions keeps older copies of stored templates side by side
+// Snapshots are taken before each template update; git tags are fetched on demand
+package versions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lancher-dev/lancher/internal/config"
+	"github.com/lancher-dev/lancher/internal/fileutil"
+	"github.com/lancher-dev/lancher/internal/gitutil"
+	"github.com/lancher-dev/lancher/internal/ignore"
+	"github.com/lancher-dev/lancher/internal/registry"
+)
+
+// Dir is the hidden directory holding stored versions inside a templates root
+const Dir = ".versions"
+
+// Version kinds
+const (
+	KindSnapshot = "snapshot"
+	KindTag      = "tag"
+)
+
+// MaxSnapshots is the number of snapshots kept per template; older ones are pruned
+const MaxSnapshots = 10
+
+const (
+	metaFileName = "version.json"
+	filesDirName = "files"
+)
+
+// Version is a stored copy of a template
+type Version struct {
+	ID        string          `json:"id"`
+	Kind      string          `json:"kind"`
+	Label     string          `json:"label,omitempty"` // Template version from its config file
+	Commit    string          `json:"commit,omitempty"`
+	Hash      string          `json:"hash,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	Entry     *registry.Entry `json:"entry,omitempty"` // Registry record at the time of the snapshot
+	Path      string          `json:"-"`               // Directory holding the template files
+}
+
+// ShortCommit returns the abbreviated commit hash
+func (v *Version) ShortCommit() string {
+	if len(v.Commit) > 7 {
+		return v.Commit[:7]
+	}
+	return v.Commit
+}
+
+// templateDir returns the directory holding all versions of a template
+func templateDir(root, name string) string {
+	return filepath.Join(root, Dir, filepath.FromSlash(name))
+}
+
+// Snapshot stores a copy of the template at templatePath, including its git data
+// entry is the current registry record, kept so it can be restored with the files
+func Snapshot(root, name, templatePath string, entry *registry.Entry) (*Version, error) {
+	v := &Version{
+		ID:        time.Now().UTC().Format("20060102-150405"),
+		Kind:      KindSnapshot,
+		CreatedAt: time.Now().UTC(),
+		Entry:     entry,
+	}
+
+	// Keep IDs unique (and never reused after pruning) when several snapshots are taken within a second
+	base := templateDir(root, name)
+	if n := lastSuffix(base, v.ID); n > 0 {
+		v.ID = fmt.Sprintf("%s-%d", v.ID, n+1)
+	}
+
+	if err := store(filepath.Join(base, v.ID), v, func(dst string) error {
+		return fileutil.CopyTree(templatePath, dst, ignore.Options{})
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := prune(root, name); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// lastSuffix returns the highest sequence number of the directories in base named id or id-N
+// Returns 0 if none exists; a directory named id counts as 1
+func lastSuffix(base, id string) int {
+	entries, _ := os.ReadDir(base)
+	last := 0
+	for _, entry := range entries {
+		n := 0
+		if entry.Name() == id {
+			n = 1
+		} else if rest, ok := strings.CutPrefix(entry.Name(), id+"-"); ok {
+			n, _ = strconv.Atoi(rest)
+		}
+		if n > last {
+			last = n
+		}
+	}
+	return last
+}
+
+// FetchTag stores the template at the given git tag of url
+// An already stored copy of the tag is returned as is
+func FetchTag(root, name, url, tag string, w io.Writer) (*Version, error) {
+	if existing, err := Find(root, name, tag); err != nil || (existing != nil && existing.Kind == KindTag) {
+		return existing, err
+	}
+
+	v := &Version{
+		ID:        tag,
+		Kind:      KindTag,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	dirName := "tag-" + strings.NewReplacer("/", "-", "\\", "-").Replace(tag)
+	err := store(filepath.Join(templateDir(root, name), dirName), v, func(dst string) error {
+		if err := gitutil.Clone(url, dst, tag, w); err != nil {
+			return err
+		}
+		commit, err := gitutil.HeadCommit(dst)
+		if err != nil {
+			return err
+		}
+		v.Commit = commit
+		// A tag never moves, so the git data is not needed
+		return os.RemoveAll(filepath.Join(dst, ".git"))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// store creates the version directory dir, fills it with populate and writes its metadata
+func store(dir string, v *Version, populate func(dst string) error) error {
+	base := filepath.Dir(dir)
+	if err := os.MkdirAll(base, 0755); err != nil {
+		return fmt.Errorf("failed to create versions directory: %w", err)
+	}
+
+	// Build in a temporary directory so an interrupted copy is never listed
+	tmp, err := os.MkdirTemp(base, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create versions directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	files := filepath.Join(tmp, filesDirName)
+	if err := os.Mkdir(files, 0755); err != nil {
+		return err
+	}
+	if err := populate(files); err != nil {
+		return err
+	}
+
+	if v.Commit == "" && gitutil.IsRepository(files) {
+		v.Commit, _ = gitutil.HeadCommit(files)
+	}
+	if cfg, _ := config.LoadConfig(files); cfg != nil {
+		v.Label = cfg.Version
+	}
+	if v.Hash, err = fileutil.HashDir(files); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmp, metaFileName), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write version metadata: %w", err)
+	}
+
+	if err := os.Rename(tmp, dir); err != nil {
+		return fmt.Errorf("failed to store version: %w", err)
+	}
+	v.Path = filepath.Join(dir, filesDirName)
+	return nil
+}
+
+// List returns the stored versions of a template, newest first
+func List(root, name string) ([]Version, error) {
+	base := templateDir(root, name)
+	entries, err := os.ReadDir(base)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read versions: %w", err)
+	}
+
+	var list []Version
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		dir := filepath.Join(base, entry.Name())
+		data, err := os.ReadFile(filepath.Join(dir, metaFileName))
+		if err != nil {
+			continue
+		}
+		var v Version
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("failed to parse version '%s': %w", entry.Name(), err)
+		}
+		v.Path = filepath.Join(dir, filesDirName)
+		list = append(list, v)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].CreatedAt.After(list[j].CreatedAt)
+	})
+	return list, nil
+}
+
+// Find returns the stored version matching ref by ID, then by label, then by commit prefix
+// Returns nil without error if no version matches
+func Find(root, name, ref string) (*Version, error) {
+	list, err := List(root, name)
+	if err != nil {
+		return nil, err
+	}
+
+	matchers := []func(v Version) bool{
+		func(v Version) bool { return v.ID == ref },
+		func(v Version) bool { return v.Label != "" && v.Label == ref },
+		func(v Version) bool { return len(ref) >= 7 && strings.HasPrefix(v.Commit, ref) },
+	}
+	for _, match := range matchers {
+		for i := range list {
+			if match(list[i]) {
+				return &list[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Discard removes a stored version
+func Discard(v *Version) error {
+	return os.RemoveAll(filepath.Dir(v.Path))
+}
+
+// Remove deletes all stored versions of a template
+func Remove(root, name string) error {
+	dir := templateDir(root, name)
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove versions: %w", err)
+	}
+	// Drop the namespace directory of namespaced names once empty
+	if parent := filepath.Dir(dir); parent != filepath.Join(root, Dir) {
+		os.Remove(parent)
+	}
+	return nil
+}
+
+// prune removes the oldest snapshots beyond MaxSnapshots
+func prune(root, name string) error {
+	list, err := List(root, name)
+	if err != nil {
+		return err
+	}
+
+	kept := 0
+	for i := range list {
+		if list[i].Kind != KindSnapshot {
+			continue
+		}
+		kept++
+		if kept > MaxSnapshots {
+			if err := Discard(&list[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
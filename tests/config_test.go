@@ -267,3 +267,137 @@ func containsMiddle(s, substr string) bool {
 	}
 	return false
 }
+
+func TestLoadUserConfig(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	configDir := filepath.Join(configHome, "lancher")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+
+	content := `templates_dir: shared/templates
+aliases:
+  work: https://git.example.com/
+create:
+  destination: projects/app
+  git: false
+  hooks: always
+  author: Jane Doe
+  variables:
+    go-version: "1.24"`
+	if err := os.WriteFile(filepath.Join(configDir, config.UserConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write user config: %v", err)
+	}
+
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() failed: %v", err)
+	}
+
+	if want := filepath.Join(configDir, "shared", "templates"); cfg.TemplatesDir != want {
+		t.Errorf("TemplatesDir = %q, want %q", cfg.TemplatesDir, want)
+	}
+	if cfg.Create.Destination != "projects/app" {
+		t.Errorf("Destination = %q, want %q", cfg.Create.Destination, "projects/app")
+	}
+	if cfg.Create.Git == nil || *cfg.Create.Git {
+		t.Errorf("Git = %v, want false", cfg.Create.Git)
+	}
+	if cfg.Create.Hooks != config.HooksAlways {
+		t.Errorf("Hooks = %q, want %q", cfg.Create.Hooks, config.HooksAlways)
+	}
+	if got := cfg.ExpandAlias("work:team/repo"); got != "https://git.example.com/team/repo" {
+		t.Errorf("ExpandAlias() = %q, want %q", got, "https://git.example.com/team/repo")
+	}
+	if got := cfg.ExpandAlias("gh:user/repo"); got != "gh:user/repo" {
+		t.Errorf("ExpandAlias() should leave unknown aliases unchanged, got %q", got)
+	}
+
+	env := cfg.HookEnv()
+	wantEnv := []string{"LANCHER_AUTHOR=Jane Doe", "LANCHER_VAR_GO_VERSION=1.24"}
+	if len(env) != len(wantEnv) {
+		t.Fatalf("HookEnv() = %v, want %v", env, wantEnv)
+	}
+	for i := range wantEnv {
+		if env[i] != wantEnv[i] {
+			t.Errorf("HookEnv()[%d] = %q, want %q", i, env[i], wantEnv[i])
+		}
+	}
+}
+
+func TestLoadUserConfigMissing(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		t.Fatalf("expected no error when user config not found, got: %v", err)
+	}
+	if cfg == nil {
+		t.Fatal("expected empty user config, got nil")
+	}
+	if cfg.TemplatesDir != "" || cfg.Create.Git != nil {
+		t.Errorf("expected zero-value user config, got: %+v", cfg)
+	}
+}
+
+func TestLoadUserConfigInvalidHooks(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	configDir := filepath.Join(configHome, "lancher")
+	os.MkdirAll(configDir, 0755)
+	os.WriteFile(filepath.Join(configDir, config.UserConfigFileName), []byte("create:\n  hooks: sometimes\n"), 0644)
+
+	if _, err := config.LoadUserConfig(); err == nil {
+		t.Error("expected error for invalid hooks policy")
+	}
+}
+
+func TestMatchesFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `name: Go API
+category: Backend
+tags:
+  - go
+  - api`
+	if err := os.WriteFile(filepath.Join(tmpDir, config.ConfigFileNames[0]), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := config.LoadConfig(tmpDir)
+	if err != nil || cfg == nil {
+		t.Fatalf("LoadConfig() = %v, %v", cfg, err)
+	}
+	if cfg.Category != "Backend" || len(cfg.Tags) != 2 {
+		t.Fatalf("unexpected category/tags: %q %v", cfg.Category, cfg.Tags)
+	}
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		tags     []string
+		category string
+		want     bool
+	}{
+		{"no filter", cfg, nil, "", true},
+		{"matching tag", cfg, []string{"go"}, "", true},
+		{"tag is case-insensitive", cfg, []string{"GO"}, "", true},
+		{"all tags required", cfg, []string{"go", "web"}, "", false},
+		{"matching category", cfg, nil, "backend", true},
+		{"other category", cfg, nil, "frontend", false},
+		{"tag and category", cfg, []string{"api"}, "Backend", true},
+		{"nil config without filter", nil, nil, "", true},
+		{"nil config with tag", nil, []string{"go"}, "", false},
+		{"nil config with category", nil, nil, "backend", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.MatchesFilter(tt.tags, tt.category); got != tt.want {
+				t.Errorf("MatchesFilter(%v, %q) = %v, want %v", tt.tags, tt.category, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,58 +1,27 @@
 package tests
 
 import (
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/lancher-dev/lancher/internal/cli/commands"
+	"github.com/lancher-dev/lancher/internal/cli/template"
 	"github.com/lancher-dev/lancher/internal/config"
 	"github.com/lancher-dev/lancher/internal/fileutil"
+	"github.com/lancher-dev/lancher/internal/gitutil"
+	"github.com/lancher-dev/lancher/internal/storage"
+	"github.com/lancher-dev/lancher/internal/versions"
 )
 
-// copyTemplate replicates the logic from create.go for testing
+// copyTemplate mirrors the create command, which copies through the shared ignore engine
 func copyTemplate(srcPath, dstPath string, cfg *config.Config) error {
-	return filepath.Walk(srcPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Calculate relative path
-		relPath, err := filepath.Rel(srcPath, path)
-		if err != nil {
-			return err
-		}
-
-		// Skip .lancher.yaml config file
-		if relPath == config.ConfigFileNames[0] {
-			return nil
-		}
-
-		// Skip .git directory (always excluded from templates)
-		if relPath == ".git" || strings.HasPrefix(relPath, ".git"+string(filepath.Separator)) {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Check ignore patterns
-		if cfg != nil && cfg.ShouldIgnore(relPath) {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Construct destination path
-		targetPath := filepath.Join(dstPath, relPath)
-
-		if info.IsDir() {
-			return os.MkdirAll(targetPath, info.Mode())
-		}
-
-		return fileutil.CopyFile(path, targetPath)
-	})
+	return fileutil.CopyTree(srcPath, dstPath, cfg.IgnoreOptions())
 }
 
 // TestCreateCommandExcludesGit verifies that .git directory is never copied from templates
@@ -152,3 +121,190 @@ func TestCreateCommandExcludesConfig(t *testing.T) {
 		t.Errorf("test.txt should be copied, but it doesn't exist")
 	}
 }
+
+// TestCreateCommandIgnoreRules verifies config ignore patterns and .lancherignore share gitignore semantics
+func TestCreateCommandIgnoreRules(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := filepath.Join(t.TempDir(), "project")
+
+	files := []string{
+		"lancher.yml",
+		"nested/.lancher.yaml",
+		"nested/.git",
+		".lancherignore",
+		"src/app/generated/types.go",
+		"src/app/main.go",
+		"tmp/cache.bin",
+		"keep.log",
+		"debug.log",
+	}
+	for _, file := range files {
+		path := filepath.Join(srcDir, file)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", file, err)
+		}
+	}
+	os.WriteFile(filepath.Join(srcDir, ".lancherignore"), []byte("*.log\n!keep.log\n"), 0644)
+
+	cfg := &config.Config{Ignore: []string{"src/**/generated", "/tmp"}}
+	if err := copyTemplate(srcDir, dstDir, cfg); err != nil {
+		t.Fatalf("copyTemplate failed: %v", err)
+	}
+
+	for _, file := range []string{"lancher.yml", "nested/.git", "src/app/generated", "tmp", "debug.log"} {
+		if _, err := os.Stat(filepath.Join(dstDir, file)); !os.IsNotExist(err) {
+			t.Errorf("%s should not be copied, but it exists", file)
+		}
+	}
+	// Only the template's own config file at the root is excluded
+	for _, file := range []string{"nested/.lancher.yaml", "src/app/main.go", "keep.log", ".lancherignore"} {
+		if _, err := os.Stat(filepath.Join(dstDir, file)); err != nil {
+			t.Errorf("%s should be copied: %v", file, err)
+		}
+	}
+}
+
+// TestCreateCommandChecksFilters verifies that filters are applied to a template given with -t
+func TestCreateCommandChecksFilters(t *testing.T) {
+	isolateStorage(t)
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "main.go"), []byte("package main"), 0644)
+	os.WriteFile(filepath.Join(src, ".lancher.yaml"), []byte("name: API\ncategory: Backend\ntags: [go]\n"), 0644)
+	if err := template.RunAdd([]string{"api", src}); err != nil {
+		t.Fatalf("RunAdd() failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		filters []string
+		wantErr bool
+	}{
+		{"matching", []string{"--tag", "go", "--category", "backend"}, false},
+		{"tag mismatch", []string{"--tag", "rust"}, true},
+		{"category mismatch", []string{"--category", "frontend"}, true},
+		{"namespace mismatch", []string{"--namespace", "acme"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "project")
+			args := append([]string{"-t", "api", "-d", dest, "--no-git", "--no-hooks"}, tt.filters...)
+			err := commands.Run(args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, statErr := os.Stat(filepath.Join(dest, "main.go")); (statErr == nil) == tt.wantErr {
+				t.Errorf("project created = %v, want %v", statErr == nil, !tt.wantErr)
+			}
+		})
+	}
+
+	// Filters cannot apply to a template that is not registered
+	dest := filepath.Join(t.TempDir(), "project")
+	if err := commands.Run([]string{"-t", src, "-d", dest, "--tag", "go", "--no-git", "--no-hooks"}); err == nil {
+		t.Error("Run() with a path and --tag succeeded, want error")
+	}
+}
+
+func TestCreateCommandVersionFromProjectRoot(t *testing.T) {
+	root := isolateStorage(t)
+	url, _ := newGitRepo(t)
+
+	// A git template checked into a project's .lancher/templates
+	project := t.TempDir()
+	tmplPath := filepath.Join(project, storage.ProjectTemplatesDir, "api")
+	if err := gitutil.Clone(url, tmplPath, "", io.Discard); err != nil {
+		t.Fatalf("Clone() failed: %v", err)
+	}
+	t.Chdir(project)
+
+	dest := filepath.Join(t.TempDir(), "app")
+	if err := commands.Run([]string{"-t", "api@v1", "-d", dest, "--no-git", "--no-hooks"}); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dest, "VERSION"))
+	if string(data) != "v1" {
+		t.Errorf("VERSION = %q, want v1", data)
+	}
+
+	// The tag is stored in the user templates dir, not inside the project
+	if _, err := os.Stat(filepath.Join(project, storage.ProjectTemplatesDir, versions.Dir)); !os.IsNotExist(err) {
+		t.Errorf("versions stored in the project root: %v", err)
+	}
+	tmpl, found, err := storage.FindTemplate("api")
+	if err != nil || !found || tmpl.Root.Scope != storage.ScopeProject {
+		t.Fatalf("FindTemplate() = %+v, %v, %v; want project template", tmpl, found, err)
+	}
+	if v, err := versions.Find(root, tmpl.QualifiedName(), "v1"); err != nil || v == nil {
+		t.Errorf("versions.Find() in user root = %v, %v; want stored tag", v, err)
+	}
+	if v, _ := versions.Find(root, "api", "v1"); v != nil {
+		t.Error("project tag stored under the name of a user template")
+	}
+}
+
+func TestLsFilesMatchesCreate(t *testing.T) {
+	root := isolateStorage(t)
+
+	// The template links to a directory outside it, which create copies as a directory
+	shared := filepath.Join(t.TempDir(), "shared")
+	os.MkdirAll(filepath.Join(shared, "lint"), 0755)
+	os.WriteFile(filepath.Join(shared, "lint", "rules.json"), []byte("{}"), 0644)
+	tmplPath := filepath.Join(root, "api")
+	os.MkdirAll(filepath.Join(tmplPath, "nested"), 0755)
+	os.WriteFile(filepath.Join(tmplPath, ".lancher.yaml"), []byte("name: API\n"), 0644)
+	os.WriteFile(filepath.Join(tmplPath, "nested", ".lancher.yaml"), []byte("name: Nested\n"), 0644)
+	os.WriteFile(filepath.Join(tmplPath, "main.go"), []byte("package main"), 0644)
+	os.Symlink("main.go", filepath.Join(tmplPath, "app.go"))
+	os.Symlink(shared, filepath.Join(tmplPath, "shared"))
+
+	for _, policy := range []string{"preserve", "follow", "skip"} {
+		t.Run(policy, func(t *testing.T) {
+			stdout, _ := captureOutput(t, "text")
+			if err := template.RunLsFiles([]string{"api", "--symlinks", policy}); err != nil {
+				t.Fatalf("RunLsFiles() failed: %v", err)
+			}
+			listed := strings.Fields(stdout.String())
+
+			dest := filepath.Join(t.TempDir(), "project")
+			if err := commands.Run([]string{"-t", "api", "-d", dest, "--symlinks", policy, "--no-git", "--no-hooks"}); err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+			var created []string
+			filepath.WalkDir(dest, func(path string, entry fs.DirEntry, err error) error {
+				if err == nil && !entry.IsDir() {
+					rel, _ := filepath.Rel(dest, path)
+					created = append(created, filepath.ToSlash(rel))
+				}
+				return err
+			})
+
+			if !equalStrings(listed, created) {
+				t.Errorf("ls-files = %v, create copied %v", listed, created)
+			}
+		})
+	}
+}
+
+func TestCreateCommandArchiveURLWithoutExtension(t *testing.T) {
+	isolateStorage(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// The link answers git requests with the archive too, so cloning yields an empty repository
+	archive := filepath.Join(t.TempDir(), "api.tar")
+	createTestTar(t, archive, nil, map[string]string{"main.go": "package main"})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-tar")
+		http.ServeFile(w, r, archive)
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "app")
+	if err := commands.Run([]string{"-t", server.URL + "/download?id=1", "-d", dest, "--no-git", "--no-hooks"}); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "main.go")); string(data) != "package main" {
+		t.Errorf("main.go = %q, want the archive contents", data)
+	}
+}
@@ -1,12 +1,21 @@
 package tests
 
 import (
+	"archive/tar"
 	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/lancher-dev/lancher/internal/fileutil"
+	"github.com/lancher-dev/lancher/internal/ignore"
+	"github.com/ulikunitz/xz"
 )
 
 func TestCopyFile(t *testing.T) {
@@ -71,6 +80,116 @@ func TestCopyDir(t *testing.T) {
 	}
 }
 
+func TestCopyDirHonoursLancherIgnore(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	srcDir := filepath.Join(tmpDir, "source")
+	os.MkdirAll(filepath.Join(srcDir, "src", "api", "generated"), 0755)
+	os.MkdirAll(filepath.Join(srcDir, "build"), 0755)
+	os.WriteFile(filepath.Join(srcDir, ".lancherignore"), []byte("src/**/generated\n/build\n*.log\n!keep.log\n"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "src", "api", "generated", "types.go"), []byte("generated"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "src", "api", "handler.go"), []byte("handler"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "build", "out.bin"), []byte("bin"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "debug.log"), []byte("log"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "keep.log"), []byte("log"), 0644)
+
+	dstDir := filepath.Join(tmpDir, "dest")
+	if err := fileutil.CopyDir(srcDir, dstDir); err != nil {
+		t.Fatalf("CopyDir() failed: %v", err)
+	}
+
+	for _, path := range []string{"src/api/generated", "build", "debug.log"} {
+		if _, err := os.Stat(filepath.Join(dstDir, path)); !os.IsNotExist(err) {
+			t.Errorf("%s should be ignored, but it was copied", path)
+		}
+	}
+	for _, path := range []string{"src/api/handler.go", "keep.log"} {
+		if _, err := os.Stat(filepath.Join(dstDir, path)); err != nil {
+			t.Errorf("%s should be copied: %v", path, err)
+		}
+	}
+}
+
+func TestHashDir(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "src"), 0755)
+	os.MkdirAll(filepath.Join(dir, ".git"), 0755)
+	os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("package main"), 0644)
+	os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref: refs/heads/main"), 0644)
+
+	first, err := fileutil.HashDir(dir)
+	if err != nil {
+		t.Fatalf("HashDir() failed: %v", err)
+	}
+
+	// .git contents do not affect the hash
+	os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref: refs/heads/other"), 0644)
+	if again, _ := fileutil.HashDir(dir); again != first {
+		t.Errorf("HashDir() changed after modifying .git: %s != %s", again, first)
+	}
+
+	// File contents do
+	os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("package app"), 0644)
+	if changed, _ := fileutil.HashDir(dir); changed == first {
+		t.Error("HashDir() should change when file contents change")
+	}
+}
+
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "src"), 0755)
+	os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("package main"), 0644)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("# app"), 0644)
+
+	// A linked directory is measured through the link
+	link := filepath.Join(t.TempDir(), "link")
+	os.Symlink(dir, link)
+
+	size, files, err := fileutil.DirSize(link)
+	if err != nil {
+		t.Fatalf("DirSize() failed: %v", err)
+	}
+	if size != 17 || files != 2 {
+		t.Errorf("DirSize() = %d, %d; want 17, 2", size, files)
+	}
+}
+
+func TestDiffTrees(t *testing.T) {
+	oldDir := filepath.Join(t.TempDir(), "old")
+	newDir := filepath.Join(t.TempDir(), "new")
+	os.MkdirAll(filepath.Join(oldDir, "src"), 0755)
+	os.MkdirAll(filepath.Join(newDir, "src"), 0755)
+
+	os.WriteFile(filepath.Join(oldDir, "same.txt"), []byte("same"), 0644)
+	os.WriteFile(filepath.Join(newDir, "same.txt"), []byte("same"), 0644)
+	os.WriteFile(filepath.Join(oldDir, "src", "main.go"), []byte("v1"), 0644)
+	os.WriteFile(filepath.Join(newDir, "src", "main.go"), []byte("v2"), 0644)
+	os.WriteFile(filepath.Join(oldDir, "removed.txt"), []byte("gone"), 0644)
+	os.WriteFile(filepath.Join(newDir, "src", "added.go"), []byte("new"), 0644)
+
+	diff, err := fileutil.DiffTrees(oldDir, newDir)
+	if err != nil {
+		t.Fatalf("DiffTrees() failed: %v", err)
+	}
+	if len(diff.Added) != 1 || diff.Added[0] != "src/added.go" {
+		t.Errorf("Added = %v, want [src/added.go]", diff.Added)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0] != "src/main.go" {
+		t.Errorf("Changed = %v, want [src/main.go]", diff.Changed)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0] != "removed.txt" {
+		t.Errorf("Removed = %v, want [removed.txt]", diff.Removed)
+	}
+
+	same, err := fileutil.DiffTrees(oldDir, oldDir)
+	if err != nil {
+		t.Fatalf("DiffTrees() failed: %v", err)
+	}
+	if !same.Empty() {
+		t.Errorf("DiffTrees() of identical trees should be empty, got %+v", same)
+	}
+}
+
 func TestRemoveDir(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -156,3 +275,542 @@ func createTestZip(zipPath string) error {
 
 	return nil
 }
+
+func TestExtractArchive(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		format   string
+		compress func(io.Writer) (io.WriteCloser, error)
+	}{
+		{"tar", "template.tar", fileutil.FormatTar, nil},
+		{"tar.gz", "template.tar.gz", fileutil.FormatTarGzip, func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		}},
+		{"tar.xz", "template.tar.xz", fileutil.FormatTarXz, func(w io.Writer) (io.WriteCloser, error) {
+			return xz.NewWriter(w)
+		}},
+		{"tar.zst", "template.tar.zst", fileutil.FormatTarZstd, func(w io.Writer) (io.WriteCloser, error) {
+			return zstd.NewWriter(w)
+		}},
+		// The format is detected from the contents, not the extension
+		{"misnamed tar.gz", "template.zip", fileutil.FormatTarGzip, func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			archivePath := filepath.Join(tmpDir, tt.fileName)
+			createTestTar(t, archivePath, tt.compress, map[string]string{
+				"file1.txt":        "content1",
+				"subdir/file2.txt": "content2",
+			})
+
+			format, err := fileutil.DetectArchive(archivePath)
+			if err != nil || format != tt.format {
+				t.Errorf("DetectArchive() = %q, %v; want %q", format, err, tt.format)
+			}
+
+			extractDir := filepath.Join(tmpDir, "extracted")
+			if err := fileutil.ExtractArchive(archivePath, extractDir, fileutil.ExtractOptions{}); err != nil {
+				t.Fatalf("ExtractArchive() failed: %v", err)
+			}
+
+			content, _ := os.ReadFile(filepath.Join(extractDir, "file1.txt"))
+			if string(content) != "content1" {
+				t.Errorf("Content mismatch: got %q, want %q", content, "content1")
+			}
+			content2, _ := os.ReadFile(filepath.Join(extractDir, "subdir", "file2.txt"))
+			if string(content2) != "content2" {
+				t.Errorf("Content mismatch in subdirectory: got %q, want %q", content2, "content2")
+			}
+		})
+	}
+}
+
+func TestExtractArchiveZip(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// A ZIP file without the .zip extension is still recognized
+	zipPath := filepath.Join(tmpDir, "template.bin")
+	if err := createTestZip(zipPath); err != nil {
+		t.Fatalf("Failed to create test ZIP: %v", err)
+	}
+
+	extractDir := filepath.Join(tmpDir, "extracted")
+	if err := fileutil.ExtractArchive(zipPath, extractDir, fileutil.ExtractOptions{}); err != nil {
+		t.Fatalf("ExtractArchive() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(extractDir, "subdir", "file2.txt")); err != nil {
+		t.Errorf("subdir/file2.txt was not extracted: %v", err)
+	}
+}
+
+func TestExtractArchiveRejectsTraversal(t *testing.T) {
+	tmpDir := t.TempDir()
+	archivePath := filepath.Join(tmpDir, "evil.tar")
+	createTestTar(t, archivePath, nil, map[string]string{"../outside.txt": "evil"})
+
+	if err := fileutil.ExtractArchive(archivePath, filepath.Join(tmpDir, "extracted"), fileutil.ExtractOptions{}); err == nil {
+		t.Error("ExtractArchive() accepted an entry outside the destination")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "outside.txt")); !os.IsNotExist(err) {
+		t.Error("file outside the destination was written")
+	}
+}
+
+func TestExtractArchiveNotArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.tar.gz")
+	os.WriteFile(path, []byte("plain text"), 0644)
+
+	if err := fileutil.ExtractArchive(path, t.TempDir(), fileutil.ExtractOptions{}); !errors.Is(err, fileutil.ErrNotArchive) {
+		t.Errorf("ExtractArchive() error = %v, want ErrNotArchive", err)
+	}
+}
+
+// createTestTar writes a tar archive with the given files, wrapped by compress if set
+func createTestTar(t *testing.T, path string, compress func(io.Writer) (io.WriteCloser, error), files map[string]string) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	defer file.Close()
+
+	var w io.Writer = file
+	if compress != nil {
+		cw, err := compress(file)
+		if err != nil {
+			t.Fatalf("Failed to create compressor: %v", err)
+		}
+		defer cw.Close()
+		w = cw
+	}
+
+	tw := tar.NewWriter(w)
+	defer tw.Close()
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write tar entry: %v", err)
+		}
+	}
+}
+
+func TestExtractArchiveTopLevel(t *testing.T) {
+	tmpDir := t.TempDir()
+	archivePath := filepath.Join(tmpDir, "repo-main.tar")
+	createTestTar(t, archivePath, nil, map[string]string{
+		"repo-main/README.md":            "readme",
+		"repo-main/repo-main/nested.txt": "nested",
+	})
+
+	// A single top-level directory is kept by default, it may be the template itself
+	kept := filepath.Join(tmpDir, "kept")
+	if err := fileutil.ExtractArchive(archivePath, kept, fileutil.ExtractOptions{}); err != nil {
+		t.Fatalf("ExtractArchive() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(kept, "repo-main", "README.md")); err != nil {
+		t.Errorf("top-level directory was not kept: %v", err)
+	}
+
+	stripped := filepath.Join(tmpDir, "stripped")
+	if err := fileutil.ExtractArchive(archivePath, stripped, fileutil.ExtractOptions{StripTopLevel: true}); err != nil {
+		t.Fatalf("ExtractArchive() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(stripped, "README.md")); err != nil {
+		t.Errorf("README.md not at the top level: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(stripped, "repo-main", "nested.txt")); err != nil {
+		t.Errorf("nested directory of the same name was lost: %v", err)
+	}
+}
+
+func TestExtractArchiveSymlinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		wantErr bool
+	}{
+		{"relative inside", "file1.txt", false},
+		{"parent inside", "../file1.txt", false},
+		{"escaping", "../../outside", true},
+		{"absolute", "/etc/passwd", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			archivePath := filepath.Join(tmpDir, "links.tar")
+			file, _ := os.Create(archivePath)
+			tw := tar.NewWriter(file)
+			tw.WriteHeader(&tar.Header{Name: "file1.txt", Mode: 0644, Size: 1, Typeflag: tar.TypeReg})
+			tw.Write([]byte("x"))
+			tw.WriteHeader(&tar.Header{Name: "sub/link", Linkname: tt.target, Mode: 0777, Typeflag: tar.TypeSymlink})
+			tw.Close()
+			file.Close()
+
+			extractDir := filepath.Join(tmpDir, "extracted")
+			err := fileutil.ExtractArchive(archivePath, extractDir, fileutil.ExtractOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractArchive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			target, err := os.Readlink(filepath.Join(extractDir, "sub", "link"))
+			if err != nil || target != tt.target {
+				t.Errorf("symlink target = %q, %v; want %q", target, err, tt.target)
+			}
+		})
+	}
+}
+
+// writeTarEntries writes a tar archive of the given headers; regular files get content "x"
+func writeTarEntries(t *testing.T, path string, headers []*tar.Header) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	defer file.Close()
+
+	tw := tar.NewWriter(file)
+	defer tw.Close()
+	for _, header := range headers {
+		if header.Typeflag == tar.TypeReg {
+			header.Size = 1
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			tw.Write([]byte("x"))
+		}
+	}
+}
+
+func TestExtractArchiveRejectsSymlinkTraversal(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []*tar.Header
+	}{
+		{"chained symlinks", []*tar.Header{
+			{Name: "d1", Linkname: ".", Mode: 0777, Typeflag: tar.TypeSymlink},
+			{Name: "d1/x", Linkname: "..", Mode: 0777, Typeflag: tar.TypeSymlink},
+			{Name: "x/pwned", Mode: 0644, Typeflag: tar.TypeReg},
+		}},
+		{"symlink through symlink", []*tar.Header{
+			{Name: "a", Linkname: ".", Mode: 0777, Typeflag: tar.TypeSymlink},
+			{Name: "b", Linkname: "a/..", Mode: 0777, Typeflag: tar.TypeSymlink},
+		}},
+		{"symlink resolved later", []*tar.Header{
+			{Name: "b", Linkname: "c/x/..", Mode: 0777, Typeflag: tar.TypeSymlink},
+			{Name: "c/", Mode: 0755, Typeflag: tar.TypeDir},
+			{Name: "c/x", Linkname: "..", Mode: 0777, Typeflag: tar.TypeSymlink},
+		}},
+		{"file through symlinked directory", []*tar.Header{
+			{Name: "up", Linkname: "sub/..", Mode: 0777, Typeflag: tar.TypeSymlink},
+			{Name: "sub/", Mode: 0755, Typeflag: tar.TypeDir},
+			{Name: "sub/link", Linkname: "..", Mode: 0777, Typeflag: tar.TypeSymlink},
+			{Name: "sub/link/pwned", Mode: 0644, Typeflag: tar.TypeReg},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			archivePath := filepath.Join(tmpDir, "evil.tar")
+			writeTarEntries(t, archivePath, tt.headers)
+
+			extractDir := filepath.Join(tmpDir, "out", "extracted")
+			if err := fileutil.ExtractArchive(archivePath, extractDir, fileutil.ExtractOptions{}); err == nil {
+				t.Error("ExtractArchive() accepted a path escaping the destination through a symlink")
+			}
+			if _, err := os.Lstat(filepath.Join(tmpDir, "out", "pwned")); !os.IsNotExist(err) {
+				t.Error("file outside the destination was written")
+			}
+		})
+	}
+}
+
+func TestExtractArchiveRejectsHardlinkThroughSymlink(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "out"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "out", "secret"), []byte("private key"), 0600)
+
+	tests := []struct {
+		name    string
+		headers []*tar.Header
+	}{
+		{"escaping directory", []*tar.Header{
+			{Name: "d1", Linkname: ".", Mode: 0777, Typeflag: tar.TypeSymlink},
+			{Name: "d1/x", Linkname: "..", Mode: 0777, Typeflag: tar.TypeSymlink},
+			{Name: "stolen", Linkname: "x/secret", Mode: 0644, Typeflag: tar.TypeLink},
+		}},
+		{"directory inside", []*tar.Header{
+			{Name: "sub/", Mode: 0755, Typeflag: tar.TypeDir},
+			{Name: "sub/file", Mode: 0644, Typeflag: tar.TypeReg},
+			{Name: "here", Linkname: "sub", Mode: 0777, Typeflag: tar.TypeSymlink},
+			{Name: "stolen", Linkname: "here/file", Mode: 0644, Typeflag: tar.TypeLink},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archivePath := filepath.Join(t.TempDir(), "evil.tar")
+			writeTarEntries(t, archivePath, tt.headers)
+
+			extractDir := filepath.Join(tmpDir, "out", strings.ReplaceAll(tt.name, " ", "-"))
+			if err := fileutil.ExtractArchive(archivePath, extractDir, fileutil.ExtractOptions{}); err == nil {
+				t.Error("ExtractArchive() accepted a hard link through a symlinked directory")
+			}
+			if _, err := os.Lstat(filepath.Join(extractDir, "stolen")); !os.IsNotExist(err) {
+				t.Error("hard link was created")
+			}
+		})
+	}
+}
+
+func TestExtractArchiveLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+	archivePath := filepath.Join(tmpDir, "big.tar.gz")
+	createTestTar(t, archivePath, func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	}, map[string]string{
+		"a.txt": strings.Repeat("a", 1000),
+		"b.txt": strings.Repeat("b", 1000),
+	})
+
+	err := fileutil.ExtractArchive(archivePath, filepath.Join(tmpDir, "size"), fileutil.ExtractOptions{MaxSize: 1500})
+	if err == nil || !strings.Contains(err.Error(), "maximum extracted size") {
+		t.Errorf("ExtractArchive() with size limit error = %v, want size error", err)
+	}
+
+	err = fileutil.ExtractArchive(archivePath, filepath.Join(tmpDir, "files"), fileutil.ExtractOptions{MaxFiles: 1})
+	if err == nil || !strings.Contains(err.Error(), "more than 1 entries") {
+		t.Errorf("ExtractArchive() with file limit error = %v, want file count error", err)
+	}
+
+	if err := fileutil.ExtractArchive(archivePath, filepath.Join(tmpDir, "ok"), fileutil.ExtractOptions{MaxSize: 2000, MaxFiles: 2}); err != nil {
+		t.Errorf("ExtractArchive() within limits failed: %v", err)
+	}
+}
+
+func TestCopyTreeSymlinks(t *testing.T) {
+	// root/shared is outside the template, root/template is copied
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "shared"), 0755)
+	os.WriteFile(filepath.Join(root, "shared", "eslint"), []byte("shared config"), 0644)
+	src := filepath.Join(root, "template")
+	os.MkdirAll(filepath.Join(src, "config"), 0755)
+	os.WriteFile(filepath.Join(src, "config", "base.json"), []byte("{}"), 0644)
+	os.Symlink("config/base.json", filepath.Join(src, "tsconfig.json"))
+	os.Symlink("config", filepath.Join(src, "conf"))
+	os.Symlink("../shared/eslint", filepath.Join(src, ".eslintrc"))
+
+	tests := []struct {
+		policy   fileutil.SymlinkPolicy
+		links    []string // Paths expected to be symlinks
+		files    []string // Paths expected to be regular files
+		absent   []string
+		contents map[string]string
+	}{
+		{
+			policy:   fileutil.SymlinkPreserve,
+			links:    []string{"tsconfig.json", "conf"},
+			files:    []string{".eslintrc"},
+			contents: map[string]string{".eslintrc": "shared config", "tsconfig.json": "{}"},
+		},
+		{
+			policy:   fileutil.SymlinkFollow,
+			files:    []string{"tsconfig.json", ".eslintrc", "conf/base.json"},
+			contents: map[string]string{".eslintrc": "shared config"},
+		},
+		{
+			policy: fileutil.SymlinkSkip,
+			files:  []string{"config/base.json"},
+			absent: []string{"tsconfig.json", "conf", ".eslintrc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "project")
+			if err := fileutil.CopyTreeWithLinks(src, dst, ignore.Options{}, tt.policy); err != nil {
+				t.Fatalf("CopyTreeWithLinks() failed: %v", err)
+			}
+
+			for _, path := range tt.links {
+				if info, err := os.Lstat(filepath.Join(dst, path)); err != nil || info.Mode()&os.ModeSymlink == 0 {
+					t.Errorf("%s is not a symlink", path)
+				}
+			}
+			for _, path := range tt.files {
+				if info, err := os.Lstat(filepath.Join(dst, path)); err != nil || !info.Mode().IsRegular() {
+					t.Errorf("%s is not a regular file", path)
+				}
+			}
+			for _, path := range tt.absent {
+				if _, err := os.Lstat(filepath.Join(dst, path)); !os.IsNotExist(err) {
+					t.Errorf("%s was copied", path)
+				}
+			}
+			for path, want := range tt.contents {
+				if got, _ := os.ReadFile(filepath.Join(dst, path)); string(got) != want {
+					t.Errorf("%s = %q, want %q", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCopyTreePreserveResolvesLinkChains(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "shared"), 0755)
+	os.WriteFile(filepath.Join(root, "shared", "eslint"), []byte("shared config"), 0644)
+	src := filepath.Join(root, "template")
+	os.MkdirAll(src, 0755)
+	os.Symlink(".", filepath.Join(src, "a"))
+	// Stays inside the tree as text, but a/.. is the parent of the tree
+	os.Symlink("a/../shared/eslint", filepath.Join(src, "eslint"))
+
+	dst := filepath.Join(t.TempDir(), "project")
+	if err := fileutil.CopyTreeWithLinks(src, dst, ignore.Options{}, fileutil.SymlinkPreserve); err != nil {
+		t.Fatalf("CopyTreeWithLinks() failed: %v", err)
+	}
+
+	if info, err := os.Lstat(filepath.Join(dst, "a")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Error("a is not a symlink")
+	}
+	if info, err := os.Lstat(filepath.Join(dst, "eslint")); err != nil || !info.Mode().IsRegular() {
+		t.Error("eslint is not a regular file")
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "eslint")); string(got) != "shared config" {
+		t.Errorf("eslint = %q, want shared config", got)
+	}
+
+	// b is followed (a cycle, since it contains the tree) or skipped, never kept as a link
+	os.Symlink("a/..", filepath.Join(src, "b"))
+	dst = filepath.Join(t.TempDir(), "project")
+	fileutil.CopyTreeWithLinks(src, dst, ignore.Options{}, fileutil.SymlinkPreserve)
+	realDst, _ := filepath.EvalSymlinks(dst)
+	if real, err := filepath.EvalSymlinks(filepath.Join(dst, "b")); err == nil && real != realDst && !strings.HasPrefix(real, realDst+string(os.PathSeparator)) {
+		t.Errorf("b resolves to %s, outside the copy", real)
+	}
+}
+
+func TestCopyTreeFollowMatchesLogicalPaths(t *testing.T) {
+	// lib links to a directory outside the template holding its own build/ and cache/
+	root := t.TempDir()
+	shared := filepath.Join(root, "shared")
+	for _, dir := range []string{"build", "cache", "src"} {
+		os.MkdirAll(filepath.Join(shared, dir), 0755)
+		os.WriteFile(filepath.Join(shared, dir, "file"), []byte(dir), 0644)
+	}
+	src := filepath.Join(root, "template")
+	os.MkdirAll(filepath.Join(src, "build"), 0755)
+	os.WriteFile(filepath.Join(src, "build", "out"), []byte("out"), 0644)
+	os.Symlink(shared, filepath.Join(src, "lib"))
+
+	// Anchored patterns apply to the template root, not to the link target
+	opts := ignore.Options{Patterns: []string{"/build", "/lib/cache"}}
+	for _, policy := range []fileutil.SymlinkPolicy{fileutil.SymlinkFollow, fileutil.SymlinkPreserve} {
+		t.Run(string(policy), func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "project")
+			if err := fileutil.CopyTreeWithLinks(src, dst, opts, policy); err != nil {
+				t.Fatalf("CopyTreeWithLinks() failed: %v", err)
+			}
+			for path, want := range map[string]bool{
+				"build/out":      false,
+				"lib/build/file": true,
+				"lib/cache/file": false,
+				"lib/src/file":   true,
+			} {
+				if _, err := os.Stat(filepath.Join(dst, filepath.FromSlash(path))); (err == nil) != want {
+					t.Errorf("%s copied = %v, want %v", path, err == nil, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCopyTreeSymlinkCycle(t *testing.T) {
+	src := t.TempDir()
+	os.Symlink(".", filepath.Join(src, "self"))
+
+	if err := fileutil.CopyTreeWithLinks(src, filepath.Join(t.TempDir(), "dst"), ignore.Options{}, fileutil.SymlinkFollow); err == nil {
+		t.Error("CopyTreeWithLinks() followed a symlink cycle")
+	}
+	// Preserving keeps the link as is
+	if err := fileutil.CopyTreeWithLinks(src, filepath.Join(t.TempDir(), "dst"), ignore.Options{}, fileutil.SymlinkPreserve); err != nil {
+		t.Errorf("CopyTreeWithLinks() failed: %v", err)
+	}
+}
+
+func TestCopyTreePermissions(t *testing.T) {
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "run.sh"), []byte("#!/bin/sh"), 0755)
+	os.WriteFile(filepath.Join(src, "secret"), []byte("x"), 0600)
+	os.Mkdir(filepath.Join(src, "private"), 0700)
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	if err := fileutil.CopyTree(src, dst, ignore.Options{}); err != nil {
+		t.Fatalf("CopyTree() failed: %v", err)
+	}
+
+	for path, want := range map[string]os.FileMode{"run.sh": 0755, "secret": 0600, "private": 0700} {
+		info, err := os.Stat(filepath.Join(dst, path))
+		if err != nil {
+			t.Fatalf("Stat(%s) failed: %v", path, err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s mode = %o, want %o", path, got, want)
+		}
+	}
+}
+
+func TestParseSymlinkPolicy(t *testing.T) {
+	if policy, err := fileutil.ParseSymlinkPolicy(""); err != nil || policy != fileutil.SymlinkPreserve {
+		t.Errorf("ParseSymlinkPolicy(\"\") = %q, %v; want preserve", policy, err)
+	}
+	if _, err := fileutil.ParseSymlinkPolicy("copy"); err == nil {
+		t.Error("ParseSymlinkPolicy(\"copy\") succeeded")
+	}
+}
+
+func TestCopyTreeManyFiles(t *testing.T) {
+	src := t.TempDir()
+	for i := 0; i < 300; i++ {
+		dir := filepath.Join(src, fmt.Sprintf("dir%d", i%10))
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.txt", i)), []byte(strings.Repeat("x", i)), 0644)
+	}
+	// Larger than a copy buffer, with content that differs per block
+	large := make([]byte, 600*1024)
+	for i := range large {
+		large[i] = byte(i % 251)
+	}
+	os.WriteFile(filepath.Join(src, "asset.bin"), large, 0644)
+	os.WriteFile(filepath.Join(src, "empty"), nil, 0644)
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	if err := fileutil.CopyTree(src, dst, ignore.Options{}); err != nil {
+		t.Fatalf("CopyTree() failed: %v", err)
+	}
+
+	diff, err := fileutil.DiffTrees(src, dst)
+	if err != nil {
+		t.Fatalf("DiffTrees() failed: %v", err)
+	}
+	if !diff.Empty() {
+		t.Errorf("copy differs from source: %+v", diff)
+	}
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/lancher-dev/lancher/internal/fuzzy"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		text      string
+		match     bool
+		positions []int
+	}{
+		{"empty query", "", "go-api", true, nil},
+		{"prefix", "go", "go-api", true, []int{0, 1}},
+		{"case insensitive", "API", "go-api", true, []int{3, 4, 5}},
+		{"subsequence", "gap", "go-api", true, []int{0, 3, 4}},
+		{"word starts preferred", "ra", "react-app", true, []int{0, 6}},
+		{"every word must match", "go web", "go-api", false, nil},
+		{"words in any order", "api go", "go-api", true, []int{0, 1, 3, 4, 5}},
+		{"out of order", "ig", "go-api", false, nil},
+		{"longer than text", "go-api-v2", "go-api", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := fuzzy.Match(tt.query, tt.text)
+			if ok != tt.match {
+				t.Fatalf("Match(%q, %q) matched = %v, want %v", tt.query, tt.text, ok, tt.match)
+			}
+			if ok && !equalInts(result.Positions, tt.positions) {
+				t.Errorf("Match(%q, %q) positions = %v, want %v", tt.query, tt.text, result.Positions, tt.positions)
+			}
+		})
+	}
+}
+
+func TestFuzzyRank(t *testing.T) {
+	texts := []string{"python-cli", "react-app", "go-api", "api-gateway", "vue-app"}
+
+	ranked := fuzzy.Rank("api", texts)
+	var got []string
+	for _, r := range ranked {
+		got = append(got, texts[r.Index])
+	}
+	// Both match a whole word; the match at the start of the text ranks first
+	if want := []string{"api-gateway", "go-api"}; !equalStrings(got, want) {
+		t.Errorf("Rank(api) = %v, want %v", got, want)
+	}
+
+	// An empty query keeps every text in order
+	if all := fuzzy.Rank("", texts); len(all) != len(texts) || all[2].Index != 2 {
+		t.Errorf("Rank(\"\") = %v, want all texts in order", all)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
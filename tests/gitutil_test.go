@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/lancher-dev/lancher/internal/gitutil"
+)
+
+// newGitRepo creates a repository with two commits on main; the first is tagged v1
+// Returns the repository URL and the hash of the first commit
+func newGitRepo(t *testing.T) (string, string) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	git("init", "--quiet", "--initial-branch=main")
+	os.WriteFile(filepath.Join(dir, "VERSION"), []byte("v1"), 0644)
+	git("add", ".")
+	git("commit", "--quiet", "-m", "first")
+	git("tag", "v1")
+	first, err := gitutil.HeadCommit(dir)
+	if err != nil {
+		t.Fatalf("HeadCommit() failed: %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, "VERSION"), []byte("v2"), 0644)
+	git("commit", "--quiet", "-am", "second")
+
+	return "file://" + dir, first
+}
+
+func TestGitCloneRef(t *testing.T) {
+	url, first := newGitRepo(t)
+
+	tests := []struct {
+		name string
+		ref  string
+		want string
+	}{
+		{"default branch", "", "v2"},
+		{"branch", "main", "v2"},
+		{"tag", "v1", "v1"},
+		{"commit", first, "v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "clone")
+			if err := gitutil.Clone(url, dest, tt.ref, io.Discard); err != nil {
+				t.Fatalf("Clone(%q) failed: %v", tt.ref, err)
+			}
+			data, err := os.ReadFile(filepath.Join(dest, "VERSION"))
+			if err != nil {
+				t.Fatalf("failed to read VERSION: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Clone(%q) checked out %q, want %q", tt.ref, data, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitCloneUnknownRef(t *testing.T) {
+	url, _ := newGitRepo(t)
+
+	dest := filepath.Join(t.TempDir(), "clone")
+	if err := gitutil.Clone(url, dest, "does-not-exist", io.Discard); err == nil {
+		t.Fatal("Clone() with unknown ref succeeded, want error")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("Clone() left the destination behind after failing")
+	}
+}
+
+func TestGitCheckoutMovesPin(t *testing.T) {
+	url, _ := newGitRepo(t)
+
+	dest := filepath.Join(t.TempDir(), "clone")
+	if err := gitutil.Clone(url, dest, "v1", io.Discard); err != nil {
+		t.Fatalf("Clone() failed: %v", err)
+	}
+	if err := gitutil.Checkout(dest, "main", io.Discard); err != nil {
+		t.Fatalf("Checkout() failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dest, "VERSION"))
+	if string(data) != "v2" {
+		t.Errorf("Checkout(main) left %q, want v2", data)
+	}
+}
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lancher-dev/lancher/internal/ignore"
+)
+
+func TestMatcherGitignoreSemantics(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		want     bool
+	}{
+		{"exact name at root", []string{"node_modules"}, "node_modules", true, true},
+		{"exact name nested", []string{"node_modules"}, "web/node_modules", true, true},
+		{"wildcard basename", []string{"*.log"}, "logs/app.log", false, true},
+		{"wildcard does not cross slash", []string{"src/*.go"}, "src/pkg/main.go", false, false},
+		{"question mark", []string{"file?.txt"}, "file1.txt", false, true},
+		{"character class", []string{"file[0-9].txt"}, "file7.txt", false, true},
+		{"negated character class", []string{"file[!0-9].txt"}, "file7.txt", false, false},
+		{"anchored matches root", []string{"/build"}, "build", true, true},
+		{"anchored ignores nested", []string{"/build"}, "app/build", true, false},
+		{"middle slash anchors", []string{"docs/api"}, "docs/api", true, true},
+		{"middle slash anchors nested", []string{"docs/api"}, "x/docs/api", true, false},
+		{"dir-only matches dir", []string{"logs/"}, "logs", true, true},
+		{"dir-only skips file", []string{"logs/"}, "logs", false, false},
+		{"dir-only nested", []string{"logs/"}, "app/logs", true, true},
+		{"leading double star", []string{"**/generated"}, "src/a/b/generated", true, true},
+		{"leading double star root", []string{"**/generated"}, "generated", true, true},
+		{"inner double star", []string{"src/**/generated"}, "src/a/b/generated", true, true},
+		{"inner double star zero dirs", []string{"src/**/generated"}, "src/generated", true, true},
+		{"inner double star other root", []string{"src/**/generated"}, "lib/generated", true, false},
+		{"trailing double star", []string{"vendor/**"}, "vendor/pkg/x.go", false, true},
+		{"trailing double star not dir itself", []string{"vendor/**"}, "vendor", true, false},
+		{"negation re-includes", []string{"*.log", "!keep.log"}, "keep.log", false, false},
+		{"negation other files still ignored", []string{"*.log", "!keep.log"}, "other.log", false, true},
+		{"last match wins", []string{"!keep.log", "*.log"}, "keep.log", false, true},
+		{"comment line", []string{"# *.log"}, "app.log", false, false},
+		{"escaped hash", []string{`\#notes`}, "#notes", false, true},
+		{"escaped bang", []string{`\!important`}, "!important", false, true},
+		{"trailing spaces trimmed", []string{"*.tmp   "}, "a.tmp", false, true},
+		{"no patterns", nil, "anything", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := ignore.NewMatcher(tt.patterns)
+			if got := m.Match(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("Match(%q, %v) with %v = %v, want %v", tt.path, tt.isDir, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherIgnoredParent(t *testing.T) {
+	m := ignore.NewMatcher([]string{"build/", "!build/keep.txt"})
+
+	// A file cannot be re-included when its parent directory is excluded
+	if !m.Ignored("build/keep.txt", false) {
+		t.Error("Ignored() should honour the excluded parent directory")
+	}
+	if m.Ignored("src/main.go", false) {
+		t.Error("Ignored() should not ignore unrelated files")
+	}
+}
+
+func TestWalkNestedIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".lancherignore":           "*.log\n/dist\n",
+		"app.log":                  "",
+		"keep.txt":                 "",
+		"dist/bundle.js":           "",
+		"src/dist/keep.js":         "",
+		"src/.lancherignore":       "!debug.log\ngenerated/\n",
+		"src/debug.log":            "",
+		"src/main.go":              "",
+		"src/generated/types.go":   "",
+		"src/other/trace.log":      "",
+		"docs/generated/readme.md": "",
+	}
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	var got []string
+	err := ignore.Walk(root, ignore.Options{Files: []string{ignore.LancherIgnoreFile}}, func(relPath string, entry os.DirEntry) error {
+		if !entry.IsDir() {
+			got = append(got, filepath.ToSlash(relPath))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() failed: %v", err)
+	}
+
+	want := []string{
+		".lancherignore",
+		"docs/generated/readme.md",
+		"keep.txt",
+		"src/.lancherignore",
+		"src/debug.log",
+		"src/dist/keep.js",
+		"src/main.go",
+	}
+	if !equalStrings(got, want) {
+		t.Errorf("Walk() visited %v, want %v", got, want)
+	}
+}
+
+func TestWalkRespectGitignore(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":                "node_modules/\ndist\n.env\n",
+		".git/info/exclude":         "*.local\n",
+		".lancherignore":            "!dist\n",
+		"node_modules/pkg/index.js": "",
+		"dist/app.js":               "",
+		".env":                      "",
+		"settings.local":            "",
+		"web/.gitignore":            "cache/\n",
+		"web/cache/data.bin":        "",
+		"web/index.html":            "",
+		"README.md":                 "",
+	}
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	opts := ignore.Options{
+		Files:     []string{ignore.LancherIgnoreFile},
+		Exclude:   []string{".git"},
+		GitIgnore: true,
+	}
+	got, err := ignore.List(root, opts)
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	for i := range got {
+		got[i] = filepath.ToSlash(got[i])
+	}
+
+	// .lancherignore takes precedence over .gitignore and re-includes dist
+	want := []string{
+		".gitignore",
+		".lancherignore",
+		"README.md",
+		"dist/app.js",
+		"web/.gitignore",
+		"web/index.html",
+	}
+	if !equalStrings(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+
+	// Without GitIgnore, git rules are not applied
+	opts.GitIgnore = false
+	all, err := ignore.List(root, opts)
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(all) != len(files)-1 {
+		t.Errorf("List() without GitIgnore returned %d files, want %d", len(all), len(files)-1)
+	}
+}
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lancher-dev/lancher/internal/cli/shared"
+	"github.com/lancher-dev/lancher/internal/cli/template"
+)
+
+func TestCommandErrorCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		code    string
+		message string
+	}{
+		{"generic", shared.FormatError("something failed"), shared.ErrCodeGeneric, "something failed"},
+		{"not found", shared.FormatNotFoundError("api"), shared.ErrCodeTemplateNotFound, "template 'api' not found"},
+		{"unknown command", shared.FormatUnknownCommandError("--bogus", "USAGE:", "lancher "), shared.ErrCodeUnknownCommand, "unknown command '--bogus'"},
+		{"unknown subcommand", shared.FormatUnknownSubcommandError("bogus", "lancher template", "USAGE:"), shared.ErrCodeUnknownSubcommand, "unknown subcommand 'bogus'"},
+		{"missing arguments", shared.FormatMissingArgsError([]string{"name", "source"}, "USAGE:"), shared.ErrCodeMissingArgument, "missing required arguments: <name>, <source>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cmdErr *shared.CommandError
+			if !errors.As(tt.err, &cmdErr) {
+				t.Fatalf("error %q is not a CommandError", tt.err)
+			}
+			if cmdErr.Code != tt.code || cmdErr.Message != tt.message {
+				t.Errorf("got code %q, message %q; want %q, %q", cmdErr.Code, cmdErr.Message, tt.code, tt.message)
+			}
+			// Human-readable errors keep their styling
+			if !strings.Contains(tt.err.Error(), shared.ColorReset) {
+				t.Errorf("Error() = %q, want styled text", tt.err.Error())
+			}
+		})
+	}
+}
+
+// captureOutput collects what commands write to stdout and stderr in the given format
+func captureOutput(t *testing.T, format string) (*bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	shared.SetOutput(&stdout, &stderr)
+	if err := shared.SetOutputFormat(format); err != nil {
+		t.Fatalf("SetOutputFormat(%s) failed: %v", format, err)
+	}
+	t.Cleanup(func() {
+		shared.SetOutput(os.Stdout, os.Stderr)
+		shared.SetOutputFormat(shared.OutputText)
+	})
+	return &stdout, &stderr
+}
+
+func TestSetOutputFormat(t *testing.T) {
+	stdout, stderr := captureOutput(t, shared.OutputJSON)
+	shared.Printf("progress")
+	if stdout.Len() != 0 || stderr.String() != "progress" {
+		t.Errorf("JSON mode wrote %q to stdout and %q to stderr, want progress on stderr", stdout, stderr)
+	}
+
+	// Selecting text again restores stdout
+	if err := shared.SetOutputFormat(shared.OutputText); err != nil {
+		t.Fatalf("SetOutputFormat(text) failed: %v", err)
+	}
+	if shared.JSONOutput() {
+		t.Error("JSONOutput() = true after selecting text output")
+	}
+	stderr.Reset()
+	shared.Printf("done")
+	if stdout.String() != "done" || stderr.Len() != 0 {
+		t.Errorf("text mode wrote %q to stdout and %q to stderr, want done on stdout", stdout, stderr)
+	}
+	if err := shared.SetOutputFormat("yaml"); err == nil {
+		t.Error("SetOutputFormat(yaml) succeeded, want error")
+	}
+}
+
+func TestJSONOutputCommands(t *testing.T) {
+	isolateStorage(t)
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "main.go"), []byte("package main"), 0644)
+	os.WriteFile(filepath.Join(src, ".lancher.yaml"), []byte("name: Demo\ndescription: A demo\n"), 0644)
+
+	stdout, stderr := captureOutput(t, shared.OutputJSON)
+	if err := template.RunAdd([]string{"demo", src}); err != nil {
+		t.Fatalf("RunAdd() failed: %v", err)
+	}
+	if stdout.Len() != 0 || stderr.Len() == 0 {
+		t.Errorf("RunAdd() progress went to stdout (%q), want stderr", stdout)
+	}
+
+	t.Run("list", func(t *testing.T) {
+		stdout.Reset()
+		if err := template.RunList(nil); err != nil {
+			t.Fatalf("RunList() failed: %v", err)
+		}
+		var out struct {
+			Templates []struct {
+				Name   string `json:"name"`
+				Config struct {
+					Description string `json:"description"`
+				} `json:"config"`
+			} `json:"templates"`
+		}
+		if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+			t.Fatalf("RunList() printed invalid JSON: %v\n%s", err, stdout)
+		}
+		if len(out.Templates) != 1 || out.Templates[0].Name != "demo" || out.Templates[0].Config.Description != "A demo" {
+			t.Errorf("RunList() = %+v, want the demo template", out.Templates)
+		}
+	})
+
+	t.Run("info", func(t *testing.T) {
+		stdout.Reset()
+		if err := template.RunInfo([]string{"demo"}); err != nil {
+			t.Fatalf("RunInfo() failed: %v", err)
+		}
+		var out struct {
+			Name      string   `json:"name"`
+			FileCount int      `json:"file_count"`
+			Files     []string `json:"files"`
+		}
+		if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+			t.Fatalf("RunInfo() printed invalid JSON: %v\n%s", err, stdout)
+		}
+		if out.Name != "demo" || !equalStrings(out.Files, []string{"main.go"}) {
+			t.Errorf("RunInfo() = %+v, want demo with main.go", out)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		stdout.Reset()
+		err := template.RunInfo([]string{"missing"})
+		if err == nil {
+			t.Fatal("RunInfo(missing) succeeded")
+		}
+		shared.PrintError(err)
+		var out struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+			t.Fatalf("PrintError() printed invalid JSON: %v\n%s", err, stdout)
+		}
+		if out.Error.Code != shared.ErrCodeTemplateNotFound || out.Error.Message != "template 'missing' not found" {
+			t.Errorf("PrintError() = %+v, want template_not_found", out.Error)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lancher-dev/lancher/internal/registry"
+)
+
+func TestRegistrySaveLoadRemove(t *testing.T) {
+	root := t.TempDir()
+
+	// Missing record is not an error
+	entry, err := registry.Load(root, "api")
+	if err != nil {
+		t.Fatalf("Load() of missing record failed: %v", err)
+	}
+	if entry != nil {
+		t.Fatalf("Load() of missing record = %+v, want nil", entry)
+	}
+
+	added := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := &registry.Entry{
+		Name:       "api",
+		SourceType: registry.SourceGitHub,
+		Source:     "gh:org/api-template",
+		Ref:        "main",
+		Commit:     "0123456789abcdef0123456789abcdef01234567",
+		AddedAt:    added,
+		UpdatedAt:  added,
+		Hash:       "sha256:abc",
+	}
+	if err := registry.Save(root, want); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	got, err := registry.Load(root, "api")
+	if err != nil || got == nil {
+		t.Fatalf("Load() = %v, %v", got, err)
+	}
+	if *got != *want {
+		t.Errorf("Load() = %+v, want %+v", got, want)
+	}
+	if !got.IsGit() {
+		t.Error("IsGit() should be true for gh sources")
+	}
+	if got.ShortCommit() != "0123456" {
+		t.Errorf("ShortCommit() = %q, want %q", got.ShortCommit(), "0123456")
+	}
+
+	// Records live in a hidden directory inside the templates root
+	if _, err := os.Stat(filepath.Join(root, registry.Dir, "api.json")); err != nil {
+		t.Errorf("expected record file: %v", err)
+	}
+
+	if err := registry.Remove(root, "api"); err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+	if entry, _ := registry.Load(root, "api"); entry != nil {
+		t.Error("record should be gone after Remove()")
+	}
+	if err := registry.Remove(root, "api"); err != nil {
+		t.Errorf("Remove() of missing record should not fail: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/lancher-dev/lancher/internal/cli/shared"
+)
+
+// stripColors removes ANSI color codes from rendered output
+func stripColors(s string) string {
+	return regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(s, "")
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+	}
+
+	for _, tt := range tests {
+		if got := shared.FormatSize(tt.bytes); got != tt.want {
+			t.Errorf("FormatSize(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTree(t *testing.T) {
+	got := shared.FormatTree([]string{"README.md", "cmd/app/main.go", "cmd/tool.go", "go.mod"})
+	want := []string{
+		"├── README.md",
+		"├── cmd/",
+		"│   ├── app/",
+		"│   │   └── main.go",
+		"│   └── tool.go",
+		"└── go.mod",
+	}
+	if !equalStrings(got, want) {
+		t.Errorf("FormatTree() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	input := strings.Join([]string{
+		"# Title #",
+		"Run `make` to **build**, see [the docs](https://example.com).",
+		"- one",
+		"  * two",
+		"> quoted",
+		"```sh",
+		"# not a heading",
+		"```",
+		"---",
+	}, "\n")
+	want := strings.Join([]string{
+		"Title",
+		"Run make to build, see the docs (https://example.com).",
+		"• one",
+		"  • two",
+		"│ quoted",
+		"    # not a heading",
+		strings.Repeat("─", 40),
+	}, "\n")
+
+	if got := stripColors(shared.RenderMarkdown(input)); got != want {
+		t.Errorf("RenderMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	got := shared.HighlightMatches("go-api", []int{0, 3, 3}, "<")
+	if want := "<g" + shared.ColorReset + "o-<a" + shared.ColorReset + "pi"; got != want {
+		t.Errorf("HighlightMatches() = %q, want %q", got, want)
+	}
+}
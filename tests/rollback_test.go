@@ -0,0 +1,280 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lancher-dev/lancher/internal/cli/shared"
+	"github.com/lancher-dev/lancher/internal/cli/template"
+	"github.com/lancher-dev/lancher/internal/config"
+	"github.com/lancher-dev/lancher/internal/gitutil"
+	"github.com/lancher-dev/lancher/internal/registry"
+	"github.com/lancher-dev/lancher/internal/storage"
+	"github.com/lancher-dev/lancher/internal/versions"
+)
+
+func TestUpdateRollback(t *testing.T) {
+	root := isolateStorage(t)
+
+	v1 := t.TempDir()
+	os.WriteFile(filepath.Join(v1, "a.txt"), []byte("one"), 0644)
+	v2 := t.TempDir()
+	os.WriteFile(filepath.Join(v2, "a.txt"), []byte("two"), 0644)
+	os.WriteFile(filepath.Join(v2, "b.txt"), []byte("new"), 0644)
+
+	if err := template.RunAdd([]string{"demo", v1}); err != nil {
+		t.Fatalf("RunAdd() failed: %v", err)
+	}
+	if err := template.RunUpdate([]string{"demo", "-d", v2}); err != nil {
+		t.Fatalf("RunUpdate() failed: %v", err)
+	}
+
+	list, err := versions.List(root, "demo")
+	if err != nil || len(list) != 1 {
+		t.Fatalf("List() after update = %d versions, %v; want 1 snapshot", len(list), err)
+	}
+
+	if err := template.RunRollback([]string{"demo"}); err != nil {
+		t.Fatalf("RunRollback() failed: %v", err)
+	}
+
+	templatePath := filepath.Join(root, "demo")
+	data, _ := os.ReadFile(filepath.Join(templatePath, "a.txt"))
+	if string(data) != "one" {
+		t.Errorf("a.txt after rollback = %q, want one", data)
+	}
+	if _, err := os.Stat(filepath.Join(templatePath, "b.txt")); !os.IsNotExist(err) {
+		t.Error("b.txt still present after rollback")
+	}
+
+	entry, err := registry.Load(root, "demo")
+	if err != nil || entry == nil {
+		t.Fatalf("registry.Load() = %v, %v", entry, err)
+	}
+	if entry.Source != v1 {
+		t.Errorf("restored source = %q, want %q", entry.Source, v1)
+	}
+
+	// The restored snapshot is consumed
+	if err := template.RunRollback([]string{"demo"}); err == nil {
+		t.Error("second RunRollback() succeeded, want no snapshots error")
+	}
+}
+
+func TestUpdateRollbackGitOnlyChange(t *testing.T) {
+	root := isolateStorage(t)
+	url, _ := newGitRepo(t)
+
+	if err := template.RunAdd([]string{"api", url, "-p"}); err != nil {
+		t.Fatalf("RunAdd() failed: %v", err)
+	}
+	templatePath := filepath.Join(root, "api")
+	before, err := gitutil.HeadCommit(templatePath)
+	if err != nil {
+		t.Fatalf("HeadCommit() failed: %v", err)
+	}
+
+	// A commit that leaves the files as they are
+	cmd := exec.Command("git", "-C", strings.TrimPrefix(url, "file://"), "commit", "--quiet", "--allow-empty", "-m", "empty")
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v\n%s", err, out)
+	}
+
+	if err := template.RunUpdate([]string{"api", "-p"}); err != nil {
+		t.Fatalf("RunUpdate() failed: %v", err)
+	}
+	if after, _ := gitutil.HeadCommit(templatePath); after == before {
+		t.Fatal("update did not move HEAD")
+	}
+	if list, err := versions.List(root, "api"); err != nil || len(list) != 1 {
+		t.Fatalf("List() after update = %d versions, %v; want the snapshot of the previous commit", len(list), err)
+	}
+
+	if err := template.RunRollback([]string{"api", "-p"}); err != nil {
+		t.Fatalf("RunRollback() failed: %v", err)
+	}
+	if after, _ := gitutil.HeadCommit(templatePath); after != before {
+		t.Errorf("HEAD after rollback = %s, want %s", after, before)
+	}
+}
+
+func TestUpdateResyncsArchiveContainingGitDir(t *testing.T) {
+	root := isolateStorage(t)
+
+	archivePath := filepath.Join(t.TempDir(), "demo.tar")
+	createTestTar(t, archivePath, nil, map[string]string{
+		".git/HEAD": "ref: refs/heads/main\n",
+		"a.txt":     "one",
+	})
+	if err := template.RunAdd([]string{"demo", archivePath}); err != nil {
+		t.Fatalf("RunAdd() failed: %v", err)
+	}
+
+	// The recorded archive is extracted again instead of pulling the copied .git directory
+	createTestTar(t, archivePath, nil, map[string]string{
+		".git/HEAD": "ref: refs/heads/main\n",
+		"a.txt":     "two",
+	})
+	if err := template.RunUpdate([]string{"demo", "-p"}); err != nil {
+		t.Fatalf("RunUpdate() failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(root, "demo", "a.txt"))
+	if string(data) != "two" {
+		t.Errorf("a.txt after update = %q, want two", data)
+	}
+}
+
+func TestUpdateNoRespectGitignore(t *testing.T) {
+	root := isolateStorage(t)
+	configDir := filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "lancher")
+	os.MkdirAll(configDir, 0755)
+	os.WriteFile(filepath.Join(configDir, config.UserConfigFileName), []byte("add:\n  respect_gitignore: true\n"), 0644)
+
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, ".gitignore"), []byte(".env\n"), 0644)
+	os.WriteFile(filepath.Join(src, ".env"), []byte("secret"), 0644)
+	if err := template.RunAdd([]string{"demo", src}); err != nil {
+		t.Fatalf("RunAdd() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "demo", ".env")); err == nil {
+		t.Fatal("expected add to skip .env when respect_gitignore is set in config")
+	}
+
+	if err := template.RunUpdate([]string{"demo", "-d", src, "--no-respect-gitignore"}); err != nil {
+		t.Fatalf("RunUpdate() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "demo", ".env")); err != nil {
+		t.Errorf("expected .env to be copied with --no-respect-gitignore: %v", err)
+	}
+
+	if err := template.RunUpdate([]string{"demo", "-d", src, "--respect-gitignore", "--no-respect-gitignore"}); err == nil {
+		t.Error("expected error when both gitignore flags are given")
+	}
+}
+
+func TestAddLocalGitRepoSkipsGitDir(t *testing.T) {
+	root := isolateStorage(t)
+	url, _ := newGitRepo(t)
+
+	if err := template.RunAdd([]string{"demo", strings.TrimPrefix(url, "file://")}); err != nil {
+		t.Fatalf("RunAdd() failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "demo", "VERSION")); err != nil {
+		t.Errorf("VERSION was not copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "demo", ".git")); !os.IsNotExist(err) {
+		t.Error(".git was copied into the store")
+	}
+}
+
+func TestAddFailureRemovesNamespace(t *testing.T) {
+	root := isolateStorage(t)
+
+	// Not an archive, so extraction fails after the namespace is created
+	archivePath := filepath.Join(t.TempDir(), "broken.tar.gz")
+	os.WriteFile(archivePath, append([]byte{0x1f, 0x8b}, "truncated"...), 0644)
+
+	if err := template.RunAdd([]string{"acme/api", archivePath}); err == nil {
+		t.Fatal("RunAdd() with a broken archive succeeded")
+	}
+	if _, err := os.Stat(filepath.Join(root, "acme")); !os.IsNotExist(err) {
+		t.Error("namespace directory left behind after a failed add")
+	}
+}
+
+func TestAddArchiveURLWithoutExtension(t *testing.T) {
+	root := isolateStorage(t)
+
+	archive := filepath.Join(t.TempDir(), "api.tar")
+	createTestTar(t, archive, nil, map[string]string{"main.go": "package main"})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-tar")
+		http.ServeFile(w, r, archive)
+	}))
+	defer server.Close()
+
+	if err := template.RunAdd([]string{"api", server.URL + "/download?id=1", "-p"}); err != nil {
+		t.Fatalf("RunAdd() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "api", "main.go")); err != nil {
+		t.Fatalf("main.go not extracted: %v", err)
+	}
+
+	// The recorded link is downloaded again, not cloned
+	createTestTar(t, archive, nil, map[string]string{"main.go": "package main // v2"})
+	if err := template.RunUpdate([]string{"api", "-p"}); err != nil {
+		t.Fatalf("RunUpdate() failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "api", "main.go")); string(data) != "package main // v2" {
+		t.Errorf("main.go after update = %q", data)
+	}
+}
+
+func TestAddArchiveStripIsOptIn(t *testing.T) {
+	root := isolateStorage(t)
+
+	archivePath := filepath.Join(t.TempDir(), "demo.tar")
+	createTestTar(t, archivePath, nil, map[string]string{"web/index.html": "one"})
+
+	if err := template.RunAdd([]string{"kept", archivePath}); err != nil {
+		t.Fatalf("RunAdd() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "kept", "web", "index.html")); err != nil {
+		t.Errorf("single top-level directory was not kept: %v", err)
+	}
+
+	if err := template.RunAdd([]string{"stripped", archivePath, "--strip"}); err != nil {
+		t.Fatalf("RunAdd() failed: %v", err)
+	}
+	// The recorded choice is applied again when the archive is re-synced
+	createTestTar(t, archivePath, nil, map[string]string{"web/index.html": "two"})
+	if err := template.RunUpdate([]string{"stripped", "-p"}); err != nil {
+		t.Fatalf("RunUpdate() failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "stripped", "index.html"))
+	if err != nil || string(data) != "two" {
+		t.Errorf("index.html after update = %q, %v; want two at the top level", data, err)
+	}
+}
+
+func TestChangingCommandsRejectProjectTemplates(t *testing.T) {
+	isolateStorage(t)
+	project := t.TempDir()
+	os.MkdirAll(filepath.Join(project, storage.ProjectTemplatesDir, "api"), 0755)
+	t.Chdir(project)
+
+	src := t.TempDir()
+	if err := template.RunAdd([]string{"web", src}); err != nil {
+		t.Fatalf("RunAdd() failed: %v", err)
+	}
+	names, err := storage.ListUserTemplates()
+	if err != nil || !equalStrings(names, []string{"web"}) {
+		t.Errorf("ListUserTemplates() = %v, %v; want [web]", names, err)
+	}
+
+	commands := map[string]func() error{
+		"remove":   func() error { return template.RunRemove([]string{"api"}) },
+		"update":   func() error { return template.RunUpdate([]string{"api", "-d", src}) },
+		"rollback": func() error { return template.RunRollback([]string{"api"}) },
+	}
+	for name, run := range commands {
+		var cmdErr *shared.CommandError
+		if err := run(); !errors.As(err, &cmdErr) || cmdErr.Code != shared.ErrCodeReadOnlyTemplate {
+			t.Errorf("%s of a project template = %v, want %s error", name, err, shared.ErrCodeReadOnlyTemplate)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(project, storage.ProjectTemplatesDir, "api")); err != nil {
+		t.Errorf("project template was changed: %v", err)
+	}
+}
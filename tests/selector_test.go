@@ -0,0 +1,228 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/lancher-dev/lancher/internal/cli/shared"
+)
+
+// selectorFruits are the options of the selector tests, in list order
+var selectorFruits = []string{"apple", "apricot", "banana", "blueberry", "cherry", "date", "elderberry", "fig", "grape", "kiwi"}
+
+// fruitOptions returns selectorFruits as options
+func fruitOptions() []shared.SelectOption {
+	options := make([]shared.SelectOption, len(selectorFruits))
+	for i, fruit := range selectorFruits {
+		options[i] = shared.SelectOption{Value: fruit, Label: fruit}
+	}
+	return options
+}
+
+// visibleRows returns the labels of the rows in the viewport; headers are prefixed with #
+func visibleRows(view shared.SelectorView) []string {
+	var rows []string
+	for _, row := range view.Rows {
+		if row.Option < 0 {
+			rows = append(rows, "#"+row.Header)
+		} else {
+			rows = append(rows, row.Label)
+		}
+	}
+	return rows
+}
+
+func TestSelector(t *testing.T) {
+	grouped := fruitOptions()
+	for i := range grouped {
+		grouped[i].Group = "early"
+		if i >= 5 {
+			grouped[i].Group = "late"
+		}
+		grouped[i].Description = "About " + grouped[i].Label
+	}
+
+	// A 10 row terminal leaves a viewport of 7 rows for the 10 fruits, paging by 6
+	tests := []struct {
+		name     string
+		options  []shared.SelectOption
+		height   int
+		keys     []string
+		current  string // Empty when nothing matches
+		rows     []string
+		position string
+		preview  string
+	}{
+		{
+			name:    "unpaged list",
+			options: fruitOptions(),
+			height:  40,
+			current: "apple",
+			rows:    selectorFruits,
+		},
+		{
+			name:     "filter without matches",
+			options:  fruitOptions(),
+			height:   10,
+			keys:     []string{"z", "z"},
+			position: "0/0",
+		},
+		{
+			name:     "filter with one match",
+			options:  fruitOptions(),
+			height:   10,
+			keys:     []string{"k", "i"},
+			current:  "kiwi",
+			rows:     []string{"kiwi"},
+			position: "1/1",
+		},
+		{
+			name:     "filter with many matches",
+			options:  fruitOptions(),
+			height:   10,
+			keys:     []string{"b", "e", "r"},
+			current:  "blueberry",
+			rows:     []string{"blueberry", "elderberry"},
+			position: "1/2",
+		},
+		{
+			name:     "cursor kept in range when the filter shrinks the list",
+			options:  fruitOptions(),
+			height:   10,
+			keys:     []string{"end", "f", "i", "g", "down", "down"},
+			current:  "fig",
+			rows:     []string{"fig"},
+			position: "1/1",
+		},
+		{
+			name:     "backspace restores the list from the top",
+			options:  fruitOptions(),
+			height:   10,
+			keys:     []string{"end", "f", "backspace"},
+			current:  "apple",
+			rows:     selectorFruits[:7],
+			position: "1/10 ↓ (PgUp/PgDn to page)",
+		},
+		{
+			name:     "page up at the top",
+			options:  fruitOptions(),
+			height:   10,
+			keys:     []string{"pgup"},
+			current:  "apple",
+			rows:     selectorFruits[:7],
+			position: "1/10 ↓ (PgUp/PgDn to page)",
+		},
+		{
+			name:     "page down",
+			options:  fruitOptions(),
+			height:   10,
+			keys:     []string{"pgdown"},
+			current:  "elderberry",
+			rows:     selectorFruits[:7],
+			position: "7/10 ↓ (PgUp/PgDn to page)",
+		},
+		{
+			name:     "page down at the bottom",
+			options:  fruitOptions(),
+			height:   10,
+			keys:     []string{"pgdown", "pgdown", "pgdown"},
+			current:  "kiwi",
+			rows:     selectorFruits[3:],
+			position: "10/10 ↑ (PgUp/PgDn to page)",
+		},
+		{
+			name:     "page up from the bottom",
+			options:  fruitOptions(),
+			height:   10,
+			keys:     []string{"end", "pgup"},
+			current:  "blueberry",
+			rows:     selectorFruits[3:],
+			position: "4/10 ↑ (PgUp/PgDn to page)",
+		},
+		{
+			name:     "scroll down past the viewport",
+			options:  fruitOptions(),
+			height:   10,
+			keys:     []string{"down", "down", "down", "down", "down", "down", "down"},
+			current:  "fig",
+			rows:     selectorFruits[1:8],
+			position: "8/10 ↑ ↓ (PgUp/PgDn to page)",
+		},
+		{
+			name:     "scroll back up",
+			options:  fruitOptions(),
+			height:   10,
+			keys:     []string{"end", "up", "up", "up", "up", "up", "up", "up", "up"},
+			current:  "apricot",
+			rows:     selectorFruits[1:8],
+			position: "2/10 ↑ ↓ (PgUp/PgDn to page)",
+		},
+		{
+			name:     "group header kept above the current option",
+			options:  grouped,
+			height:   10,
+			keys:     []string{"end", "pgup"},
+			current:  "cherry",
+			rows:     []string{"cherry", "#late", "date", "elderberry", "fig", "grape"},
+			position: "5/10 ↑ ↓ (PgUp/PgDn to page)",
+			preview:  "About cherry",
+		},
+		{
+			name:     "description preview",
+			options:  grouped,
+			height:   10,
+			keys:     []string{"down"},
+			current:  "apricot",
+			rows:     []string{"#early", "apple", "apricot", "banana", "blueberry", "cherry"},
+			position: "2/10 ↓ (PgUp/PgDn to page)",
+			preview:  "About apricot",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := shared.NewSelector("Pick", "", 2, 80, tt.height, tt.options)
+			for _, key := range tt.keys {
+				if !s.HandleKey(key, true) {
+					t.Fatalf("HandleKey(%q) = false", key)
+				}
+			}
+
+			current := ""
+			if i := s.Current(); i >= 0 {
+				current = tt.options[i].Label
+			}
+			if current != tt.current {
+				t.Errorf("current = %q, want %q", current, tt.current)
+			}
+
+			view := s.View()
+			if got := visibleRows(view); !equalStrings(got, tt.rows) {
+				t.Errorf("rows = %v, want %v", got, tt.rows)
+			}
+			if view.Position != tt.position {
+				t.Errorf("position = %q, want %q", view.Position, tt.position)
+			}
+			if view.Preview != tt.preview {
+				t.Errorf("preview = %q, want %q", view.Preview, tt.preview)
+			}
+			for _, row := range view.Rows {
+				if row.Current != (row.Option >= 0 && row.Label == tt.current) {
+					t.Errorf("row %q marked current = %v", row.Label, row.Current)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectorKeysLeftToCaller(t *testing.T) {
+	s := shared.NewSelector("Pick", "", 6, 80, 10, fruitOptions())
+	if s.HandleKey("space", false) {
+		t.Error("HandleKey(space) = true with typeSpace false, want false")
+	}
+	if s.HandleKey("\x01", true) {
+		t.Error("HandleKey(ctrl+a) = true, want false")
+	}
+	if s.Query() != "" {
+		t.Errorf("Query() = %q, want empty", s.Query())
+	}
+}
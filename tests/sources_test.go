@@ -0,0 +1,380 @@
+package tests
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lancher-dev/lancher/internal/fileutil"
+	"github.com/lancher-dev/lancher/internal/sources"
+)
+
+func TestIsSource(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"api", false},
+		{"platform/go-service", false},
+		{"api@v1.0.0", false},
+		{"./templates/api", true},
+		{"../api", true},
+		{"/srv/templates/api", true},
+		{"~/templates/api", true},
+		{"./api.zip", true},
+		{"gh:org/repo", true},
+		{"gl:group/repo@v2", true},
+		{"https://example.com/org/repo.git", true},
+		{"git@github.com:org/repo.git", true},
+		{"https://artifacts.example.com/api.tar.gz", true},
+	}
+
+	for _, tt := range tests {
+		if got := sources.IsSource(tt.value); got != tt.want {
+			t.Errorf("IsSource(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSplitRef(t *testing.T) {
+	tests := []struct {
+		source, wantSource, wantRef string
+	}{
+		{"gh:org/repo", "gh:org/repo", ""},
+		{"gh:org/repo@v1.2.0", "gh:org/repo", "v1.2.0"},
+		{"https://example.com/org/repo.git@main", "https://example.com/org/repo.git", "main"},
+		{"git@github.com:org/repo.git", "git@github.com:org/repo.git", ""},
+		{"git@github.com:org/repo.git@abc1234", "git@github.com:org/repo.git", "abc1234"},
+		{"gh:org/repo@feature/x", "gh:org/repo", "feature/x"},
+		{"https://user@example.com/org/repo.git", "https://user@example.com/org/repo.git", ""},
+		{"https://user@example.com/org/repo.git@feature/x", "https://user@example.com/org/repo.git", "feature/x"},
+	}
+
+	for _, tt := range tests {
+		source, ref := sources.SplitRef(tt.source)
+		if source != tt.wantSource || ref != tt.wantRef {
+			t.Errorf("SplitRef(%q) = %q, %q; want %q, %q", tt.source, source, ref, tt.wantSource, tt.wantRef)
+		}
+	}
+}
+
+func TestSplitRemote(t *testing.T) {
+	tests := []struct {
+		source, wantRepo, wantRef, wantSubdir string
+	}{
+		{"gh:org/repo", "gh:org/repo", "", ""},
+		{"gh:org/repo@feature/x", "gh:org/repo", "feature/x", ""},
+		{"gh:org/repo//services/go@feature/x", "gh:org/repo", "feature/x", "services/go"},
+		{"gh:org/repo@feature/x//services/go", "gh:org/repo", "feature/x", "services/go"},
+		{"https://example.com/org/repo.git//api@v1", "https://example.com/org/repo.git", "v1", "api"},
+	}
+
+	for _, tt := range tests {
+		repo, ref, subdir := sources.SplitRemote(tt.source)
+		if repo != tt.wantRepo || ref != tt.wantRef || subdir != tt.wantSubdir {
+			t.Errorf("SplitRemote(%q) = %q, %q, %q; want %q, %q, %q", tt.source, repo, ref, subdir, tt.wantRepo, tt.wantRef, tt.wantSubdir)
+		}
+	}
+}
+
+func TestFetchCachesSource(t *testing.T) {
+	url, _ := newGitRepo(t)
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	dir, err := sources.Fetch(url, "v1", io.Discard)
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+	if !strings.HasPrefix(dir, filepath.Join(cacheHome, "lancher", "sources")) {
+		t.Errorf("Fetch() = %q, want a directory in the cache", dir)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "VERSION"))
+	if string(data) != "v1" {
+		t.Errorf("fetched VERSION = %q, want v1", data)
+	}
+
+	// A second fetch reuses the cached copy
+	again, err := sources.Fetch(url, "v1", io.Discard)
+	if err != nil || again != dir {
+		t.Errorf("second Fetch() = %q, %v; want %q", again, err, dir)
+	}
+
+	// Another ref is cached separately
+	head, err := sources.Fetch(url, "", io.Discard)
+	if err != nil {
+		t.Fatalf("Fetch() of default branch failed: %v", err)
+	}
+	if head == dir {
+		t.Error("Fetch() of a different ref reused the same cache entry")
+	}
+	data, _ = os.ReadFile(filepath.Join(head, "VERSION"))
+	if string(data) != "v2" {
+		t.Errorf("fetched VERSION = %q, want v2", data)
+	}
+}
+
+func TestSplitSubdir(t *testing.T) {
+	tests := []struct {
+		source, wantSource, wantSubdir string
+	}{
+		{"gh:org/repo", "gh:org/repo", ""},
+		{"gh:org/templates//services/go", "gh:org/templates", "services/go"},
+		{"https://example.com/org/repo.git", "https://example.com/org/repo.git", ""},
+		{"https://example.com/org/repo.git//api/", "https://example.com/org/repo.git", "api"},
+		{"file:///srv/repo//web", "file:///srv/repo", "web"},
+	}
+
+	for _, tt := range tests {
+		source, subdir := sources.SplitSubdir(tt.source)
+		if source != tt.wantSource || subdir != tt.wantSubdir {
+			t.Errorf("SplitSubdir(%q) = %q, %q; want %q, %q", tt.source, source, subdir, tt.wantSource, tt.wantSubdir)
+		}
+	}
+}
+
+func TestCleanSubdir(t *testing.T) {
+	tests := []struct {
+		subdir  string
+		want    string
+		wantErr bool
+	}{
+		{"services/go", "services/go", false},
+		{"services//go/", "services/go", false},
+		{"./api", "api", false},
+		{"", "", true},
+		{".", "", true},
+		{"/etc", "", true},
+		{"../outside", "", true},
+		{"api/../../outside", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := sources.CleanSubdir(tt.subdir)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("CleanSubdir(%q) = %q, %v; want %q, error %v", tt.subdir, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCloneSubdir(t *testing.T) {
+	url, _ := newGitRepo(t)
+
+	// Add a template directory to the repository
+	repo := strings.TrimPrefix(url, "file://")
+	os.MkdirAll(filepath.Join(repo, "services", "api"), 0755)
+	os.WriteFile(filepath.Join(repo, "services", "api", "main.go"), []byte("package main"), 0644)
+	cmd := exec.Command("git", "-C", repo, "add", ".")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git add failed: %v\n%s", err, out)
+	}
+	cmd = exec.Command("git", "-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "api")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v\n%s", err, out)
+	}
+
+	dest := filepath.Join(t.TempDir(), "api")
+	commit, err := sources.CloneSubdir(url, "services/api", dest, "", io.Discard)
+	if err != nil {
+		t.Fatalf("CloneSubdir() failed: %v", err)
+	}
+	if len(commit) != 40 {
+		t.Errorf("CloneSubdir() commit = %q, want a full hash", commit)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "main.go")); err != nil {
+		t.Errorf("main.go not copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "VERSION")); !os.IsNotExist(err) {
+		t.Error("files outside the subdirectory were copied")
+	}
+	if _, err := os.Stat(filepath.Join(dest, ".git")); !os.IsNotExist(err) {
+		t.Error(".git directory was copied")
+	}
+
+	// The subdirectory does not exist at the v1 tag
+	if _, err := sources.CloneSubdir(url, "services/api", filepath.Join(t.TempDir(), "old"), "v1", io.Discard); err == nil {
+		t.Error("CloneSubdir() of a missing subdirectory succeeded")
+	}
+}
+
+func TestIsArchiveURL(t *testing.T) {
+	tests := []struct {
+		source  string
+		archive bool
+		git     bool
+	}{
+		{"https://artifacts.example.com/templates/api.tar.gz", true, false},
+		{"https://github.com/org/repo/archive/refs/tags/v1.tar.gz?download=1", true, false},
+		{"http://host/api.zip", true, false},
+		{"https://example.com/org/repo.git", false, true},
+		{"https://example.com/org/repo", false, true},
+		{"./api.tar.gz", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := sources.IsArchiveURL(tt.source); got != tt.archive {
+			t.Errorf("IsArchiveURL(%q) = %v, want %v", tt.source, got, tt.archive)
+		}
+		if got := sources.IsGitURL(tt.source); got != tt.git {
+			t.Errorf("IsGitURL(%q) = %v, want %v", tt.source, got, tt.git)
+		}
+	}
+}
+
+func TestDetectArchiveURL(t *testing.T) {
+	// Detection never makes a request; links without an extension are probed only after a failed clone
+	tests := []struct {
+		source   string
+		checksum string
+		want     bool
+	}{
+		{"https://example.com/api.tar.gz", "", true},
+		{"https://example.com/download", "", false},
+		{"https://example.com/org/repo", strings.Repeat("0", 64), true},
+		{"./download", strings.Repeat("0", 64), false},
+	}
+
+	for _, tt := range tests {
+		if got := sources.DetectArchiveURL(tt.source, tt.checksum); got != tt.want {
+			t.Errorf("DetectArchiveURL(%q, %q) = %v, want %v", tt.source, tt.checksum, got, tt.want)
+		}
+	}
+}
+
+func TestProbeArchiveURL(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		switch r.URL.Path {
+		case "/download", "/repo.git":
+			w.Header().Set("Content-Type", "application/x-gzip")
+		case "/zip":
+			w.Header().Set("Content-Type", "application/zip; charset=binary")
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{server.URL + "/download", true},
+		{server.URL + "/zip", true},
+		{server.URL + "/org/repo", false},
+		// Git URLs are never requested
+		{server.URL + "/repo.git", false},
+		{"https://github.com/org/repo", false},
+		{"https://www.gitlab.com/org/repo", false},
+		{"./download", false},
+	}
+
+	for _, tt := range tests {
+		if got := sources.ProbeArchiveURL(tt.source); got != tt.want {
+			t.Errorf("ProbeArchiveURL(%q) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+	if want := []string{"/download", "/zip", "/org/repo"}; !equalStrings(requested, want) {
+		t.Errorf("requested paths = %v, want %v", requested, want)
+	}
+}
+
+func TestDownloadSizeLimit(t *testing.T) {
+	body := strings.Repeat("x", 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// Flushing before writing leaves the length unknown to the client
+			w.(http.Flusher).Flush()
+		}
+		io.WriteString(w, body)
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/sized", "/chunked"} {
+		dest := filepath.Join(t.TempDir(), "archive")
+		if _, err := sources.Download(server.URL+path, dest, "", 99); err == nil || !strings.Contains(err.Error(), "maximum size") {
+			t.Errorf("Download(%s) over the limit error = %v, want maximum size error", path, err)
+		}
+		if _, err := os.Stat(dest); !os.IsNotExist(err) {
+			t.Errorf("Download(%s) left a partial file behind", path)
+		}
+
+		if _, err := sources.Download(server.URL+path, dest, "", 100); err != nil {
+			t.Errorf("Download(%s) at the limit failed: %v", path, err)
+		}
+		if data, _ := os.ReadFile(dest); string(data) != body {
+			t.Errorf("Download(%s) wrote %d bytes, want %d", path, len(data), len(body))
+		}
+	}
+}
+
+func TestNormalizeSHA256(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		checksum string
+		want     string
+		wantErr  bool
+	}{
+		{sum, sum, false},
+		{strings.ToUpper(sum), sum, false},
+		{"sha256:" + sum, sum, false},
+		{sum[:10], "", true},
+		{strings.Repeat("zz", 32), "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := sources.NormalizeSHA256(tt.checksum)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeSHA256(%q) = %q, %v; want %q, error %v", tt.checksum, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFetchArchive(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "api.tar.gz")
+	createTestTar(t, archive, func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	}, map[string]string{"main.go": "package main"})
+	data, _ := os.ReadFile(archive)
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.FileServer(http.Dir(filepath.Dir(archive))))
+	defer server.Close()
+	url := server.URL + "/api.tar.gz"
+
+	dest := t.TempDir()
+	got, err := sources.FetchArchive(url, dest, checksum, fileutil.ExtractOptions{})
+	if err != nil {
+		t.Fatalf("FetchArchive() failed: %v", err)
+	}
+	if got != checksum {
+		t.Errorf("FetchArchive() checksum = %q, want %q", got, checksum)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "main.go")); err != nil {
+		t.Errorf("main.go not extracted: %v", err)
+	}
+
+	// A wrong checksum is rejected before anything is extracted
+	dest = t.TempDir()
+	if _, err := sources.FetchArchive(url, dest, strings.Repeat("0", 64), fileutil.ExtractOptions{}); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("FetchArchive() with wrong checksum error = %v, want checksum mismatch", err)
+	}
+	if entries, _ := os.ReadDir(dest); len(entries) != 0 {
+		t.Error("FetchArchive() extracted an unverified archive")
+	}
+
+	if _, err := sources.FetchArchive(server.URL+"/missing.tar.gz", t.TempDir(), "", fileutil.ExtractOptions{}); err == nil {
+		t.Error("FetchArchive() of a missing file succeeded")
+	}
+}
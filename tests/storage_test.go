@@ -8,7 +8,19 @@ import (
 	"github.com/lancher-dev/lancher/internal/storage"
 )
 
+// isolateStorage points the templates directory at a fresh temp directory
+func isolateStorage(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "templates")
+	t.Setenv(storage.EnvTemplatesDir, dir)
+	t.Setenv(storage.EnvHome, "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	return dir
+}
+
 func TestGetTemplatesDir(t *testing.T) {
+	isolateStorage(t)
+
 	dir, err := storage.GetTemplatesDir()
 	if err != nil {
 		t.Fatalf("GetTemplatesDir() failed: %v", err)
@@ -30,6 +42,8 @@ func TestGetTemplatesDir(t *testing.T) {
 }
 
 func TestGetTemplatePath(t *testing.T) {
+	isolateStorage(t)
+
 	path, err := storage.GetTemplatePath("test-template")
 	if err != nil {
 		t.Fatalf("GetTemplatePath() failed: %v", err)
@@ -45,6 +59,8 @@ func TestGetTemplatePath(t *testing.T) {
 }
 
 func TestTemplateExists(t *testing.T) {
+	isolateStorage(t)
+
 	// Test non-existent template
 	exists, err := storage.TemplateExists("non-existent-template-12345")
 	if err != nil {
@@ -57,6 +73,8 @@ func TestTemplateExists(t *testing.T) {
 }
 
 func TestListTemplates(t *testing.T) {
+	isolateStorage(t)
+
 	templates, err := storage.ListTemplates()
 	if err != nil {
 		t.Fatalf("ListTemplates() failed: %v", err)
@@ -67,3 +85,221 @@ func TestListTemplates(t *testing.T) {
 		t.Error("ListTemplates() should return empty slice, not nil")
 	}
 }
+
+func TestGetTemplatesDirFromUserConfig(t *testing.T) {
+	isolateStorage(t)
+	t.Setenv(storage.EnvTemplatesDir, "")
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	templatesDir := filepath.Join(t.TempDir(), "team-templates")
+	configDir := filepath.Join(configHome, "lancher")
+	os.MkdirAll(configDir, 0755)
+	os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte("templates_dir: "+templatesDir+"\n"), 0644)
+
+	dir, err := storage.GetTemplatesDir()
+	if err != nil {
+		t.Fatalf("GetTemplatesDir() failed: %v", err)
+	}
+	if dir != templatesDir {
+		t.Errorf("GetTemplatesDir() = %q, want %q", dir, templatesDir)
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		t.Errorf("GetTemplatesDir() should create directory: %s", dir)
+	}
+}
+
+func TestGetTemplatesDirPrecedence(t *testing.T) {
+	isolateStorage(t)
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configDir := filepath.Join(configHome, "lancher")
+	os.MkdirAll(configDir, 0755)
+	os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte("templates_dir: "+filepath.Join(t.TempDir(), "from-config")+"\n"), 0644)
+
+	home := t.TempDir()
+	envDir := filepath.Join(t.TempDir(), "from-env")
+	flagDir := filepath.Join(t.TempDir(), "from-flag")
+
+	// LANCHER_HOME takes precedence over the user config
+	t.Setenv(storage.EnvTemplatesDir, "")
+	t.Setenv(storage.EnvHome, home)
+	if dir, _ := storage.GetTemplatesDir(); dir != filepath.Join(home, "templates") {
+		t.Errorf("with %s: GetTemplatesDir() = %q, want %q", storage.EnvHome, dir, filepath.Join(home, "templates"))
+	}
+
+	// LANCHER_TEMPLATES_DIR takes precedence over LANCHER_HOME
+	t.Setenv(storage.EnvTemplatesDir, envDir)
+	if dir, _ := storage.GetTemplatesDir(); dir != envDir {
+		t.Errorf("with %s: GetTemplatesDir() = %q, want %q", storage.EnvTemplatesDir, dir, envDir)
+	}
+
+	// The --templates-dir flag takes precedence over everything
+	if err := storage.SetTemplatesDir(flagDir); err != nil {
+		t.Fatalf("SetTemplatesDir() failed: %v", err)
+	}
+	defer storage.SetTemplatesDir("")
+	if dir, _ := storage.GetTemplatesDir(); dir != flagDir {
+		t.Errorf("with override: GetTemplatesDir() = %q, want %q", dir, flagDir)
+	}
+}
+
+func TestProjectTemplatesShadowUserTemplates(t *testing.T) {
+	userDir := isolateStorage(t)
+
+	// Project with a nested working directory
+	project := t.TempDir()
+	projectTemplates := filepath.Join(project, storage.ProjectTemplatesDir)
+	os.MkdirAll(filepath.Join(projectTemplates, "api"), 0755)
+	os.MkdirAll(filepath.Join(projectTemplates, "cli"), 0755)
+	workDir := filepath.Join(project, "services", "billing")
+	os.MkdirAll(workDir, 0755)
+	t.Chdir(workDir)
+
+	os.MkdirAll(filepath.Join(userDir, "api"), 0755)
+	os.MkdirAll(filepath.Join(userDir, "web"), 0755)
+
+	names, err := storage.ListTemplates()
+	if err != nil {
+		t.Fatalf("ListTemplates() failed: %v", err)
+	}
+	if want := []string{"api", "cli", "web"}; !equalStrings(names, want) {
+		t.Errorf("ListTemplates() = %v, want %v", names, want)
+	}
+
+	all, err := storage.ListAllTemplates()
+	if err != nil {
+		t.Fatalf("ListAllTemplates() failed: %v", err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("ListAllTemplates() returned %d templates, want 4", len(all))
+	}
+	if all[0].Name != "api" || all[0].Root.Scope != storage.ScopeProject || all[0].Shadowed {
+		t.Errorf("first 'api' should be the active project template, got %+v", all[0])
+	}
+	if all[1].Name != "api" || all[1].Root.Scope != storage.ScopeUser || !all[1].Shadowed {
+		t.Errorf("second 'api' should be the shadowed user template, got %+v", all[1])
+	}
+
+	tmpl, found, err := storage.FindTemplate("api")
+	if err != nil || !found {
+		t.Fatalf("FindTemplate() = %v, %v", found, err)
+	}
+	if tmpl.Path != filepath.Join(projectTemplates, "api") {
+		t.Errorf("FindTemplate() path = %q, want project template", tmpl.Path)
+	}
+
+	if _, found, _ := storage.FindTemplate("missing"); found {
+		t.Error("FindTemplate() should not find a missing template")
+	}
+}
+
+func TestNamespacedTemplates(t *testing.T) {
+	dir := isolateStorage(t)
+
+	for _, name := range []string{"platform/api", "web/api"} {
+		if err := storage.CreateNamespace(name); err != nil {
+			t.Fatalf("CreateNamespace(%q) failed: %v", name, err)
+		}
+		path, _ := storage.GetTemplatePath(name)
+		os.MkdirAll(path, 0755)
+	}
+	os.MkdirAll(filepath.Join(dir, "api", "src"), 0755)
+
+	names, err := storage.ListTemplates()
+	if err != nil {
+		t.Fatalf("ListTemplates() failed: %v", err)
+	}
+	if want := []string{"api", "platform/api", "web/api"}; !equalStrings(names, want) {
+		t.Errorf("ListTemplates() = %v, want %v", names, want)
+	}
+
+	tmpl, found, err := storage.FindTemplate("platform/api")
+	if err != nil || !found {
+		t.Fatalf("FindTemplate(platform/api) = %v, %v", found, err)
+	}
+	if want := filepath.Join(dir, "platform", "api"); tmpl.Path != want {
+		t.Errorf("FindTemplate(platform/api).Path = %q, want %q", tmpl.Path, want)
+	}
+
+	// Directories inside a template are not namespaced templates, and namespaces are not templates
+	for _, name := range []string{"api/src", "platform"} {
+		if exists, _ := storage.TemplateExists(name); exists {
+			t.Errorf("TemplateExists(%q) = true, want false", name)
+		}
+	}
+	if err := storage.CreateNamespace("api/other"); err == nil {
+		t.Error("CreateNamespace(api/other) succeeded inside a template")
+	}
+	if err := storage.CreateNamespace("platform"); err == nil {
+		t.Error("CreateNamespace(platform) succeeded for a namespace name")
+	}
+
+	// The namespace directory is removed with its last template
+	path, _ := storage.GetTemplatePath("web/api")
+	os.RemoveAll(path)
+	if err := storage.RemoveEmptyNamespace("web/api"); err != nil {
+		t.Fatalf("RemoveEmptyNamespace() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "web")); !os.IsNotExist(err) {
+		t.Error("empty namespace directory was not removed")
+	}
+}
+
+func TestLinkedTemplates(t *testing.T) {
+	dir := isolateStorage(t)
+	target := t.TempDir()
+	os.WriteFile(filepath.Join(target, "README.md"), []byte("live"), 0644)
+
+	path, err := storage.CreateLink("live", target)
+	if err != nil {
+		t.Fatalf("CreateLink() failed: %v", err)
+	}
+	if want := filepath.Join(dir, "live"); path != want {
+		t.Errorf("CreateLink() = %q, want %q", path, want)
+	}
+	if got, linked := storage.LinkTarget(path); !linked || got != target {
+		t.Errorf("LinkTarget() = %q, %v; want %q, true", got, linked, target)
+	}
+
+	os.MkdirAll(filepath.Join(dir, "copied"), 0755)
+	if _, linked := storage.LinkTarget(filepath.Join(dir, "copied")); linked {
+		t.Error("LinkTarget() reported a stored copy as linked")
+	}
+
+	all, err := storage.ListAllTemplates()
+	if err != nil {
+		t.Fatalf("ListAllTemplates() failed: %v", err)
+	}
+	linked := map[string]bool{}
+	for _, tmpl := range all {
+		linked[tmpl.Name] = tmpl.Linked
+	}
+	if want := map[string]bool{"copied": false, "live": true}; len(linked) != 2 || linked["live"] != want["live"] || linked["copied"] != want["copied"] {
+		t.Errorf("ListAllTemplates() linked = %v, want %v", linked, want)
+	}
+
+	// A link whose directory is gone is still a template, so it can be reported and removed
+	os.RemoveAll(target)
+	if exists, _ := storage.TemplateExists("live"); !exists {
+		t.Error("TemplateExists(live) = false after the linked directory was removed")
+	}
+	names, _ := storage.ListTemplates()
+	if want := []string{"copied", "live"}; !equalStrings(names, want) {
+		t.Errorf("ListTemplates() = %v, want %v", names, want)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
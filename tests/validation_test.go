@@ -19,7 +19,15 @@ func TestSanitizeTemplateName(t *testing.T) {
 		{"empty string", "", true},
 		{"dot", ".", true},
 		{"double dot", "..", true},
-		{"contains slash", "my/template", true},
+		{"hidden name", ".registry", true},
+		{"contains at sign", "api@v1", true},
+		{"namespaced", "platform/go-service", false},
+		{"nested namespace", "a/b/c", true},
+		{"leading slash", "/template", true},
+		{"trailing slash", "platform/", true},
+		{"namespace traversal", "../template", true},
+		{"name traversal", "platform/..", true},
+		{"hidden namespaced name", "platform/.registry", true},
 		{"contains backslash", "my\\template", true},
 	}
 
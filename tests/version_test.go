@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/lancher-dev/lancher/internal/version"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		v1, v2 string
+		want   int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.9.0", "1.10.0", -1},
+		{"2.0", "1.99.99", 1},
+		{"1.2", "1.2.0", 0},
+		{"1.2.0-rc.1", "1.2.0", -1},
+		{"1.2.0", "1.2.0-rc.1", 1},
+		{"1.2.0-rc.2", "1.2.0-rc.10", -1},
+		{"1.2.0-alpha", "1.2.0-beta", -1},
+		{"1.2.0-1", "1.2.0-alpha", -1},
+		{"1.2.0-rc", "1.2.0-rc.1", -1},
+		{"1.2.0+build.5", "1.2.0", 0},
+	}
+
+	for _, tt := range tests {
+		if got := version.Compare(tt.v1, tt.v2); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.v1, tt.v2, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lancher-dev/lancher/internal/registry"
+	"github.com/lancher-dev/lancher/internal/versions"
+)
+
+func TestVersionsSnapshotFind(t *testing.T) {
+	root := t.TempDir()
+	templatePath := filepath.Join(root, "api")
+	os.MkdirAll(templatePath, 0755)
+	os.WriteFile(filepath.Join(templatePath, ".lancher.yaml"), []byte("version: 1.0.0\n"), 0644)
+	os.WriteFile(filepath.Join(templatePath, "main.go"), []byte("package main\n"), 0644)
+
+	entry := &registry.Entry{Name: "api", SourceType: registry.SourceLocal, Source: "/src/api"}
+	snapshot, err := versions.Snapshot(root, "api", templatePath, entry)
+	if err != nil {
+		t.Fatalf("Snapshot() failed: %v", err)
+	}
+	if snapshot.Label != "1.0.0" {
+		t.Errorf("Label = %q, want 1.0.0", snapshot.Label)
+	}
+
+	// The snapshot is unaffected by later changes to the template
+	os.WriteFile(filepath.Join(templatePath, "main.go"), []byte("package changed\n"), 0644)
+	data, err := os.ReadFile(filepath.Join(snapshot.Path, "main.go"))
+	if err != nil || string(data) != "package main\n" {
+		t.Errorf("snapshot main.go = %q, %v", data, err)
+	}
+
+	for _, ref := range []string{snapshot.ID, "1.0.0"} {
+		found, err := versions.Find(root, "api", ref)
+		if err != nil {
+			t.Fatalf("Find(%q) failed: %v", ref, err)
+		}
+		if found == nil || found.ID != snapshot.ID {
+			t.Errorf("Find(%q) = %+v, want snapshot %s", ref, found, snapshot.ID)
+			continue
+		}
+		if found.Entry == nil || found.Entry.Source != "/src/api" {
+			t.Errorf("Find(%q) entry = %+v, want recorded registry entry", ref, found.Entry)
+		}
+	}
+
+	if found, _ := versions.Find(root, "api", "2.0.0"); found != nil {
+		t.Errorf("Find(2.0.0) = %+v, want nil", found)
+	}
+
+	if err := versions.Discard(snapshot); err != nil {
+		t.Fatalf("Discard() failed: %v", err)
+	}
+	list, _ := versions.List(root, "api")
+	if len(list) != 0 {
+		t.Errorf("List() after Discard() = %d versions, want 0", len(list))
+	}
+}
+
+func TestVersionsSnapshotPrune(t *testing.T) {
+	root := t.TempDir()
+	templatePath := filepath.Join(root, "api")
+	os.MkdirAll(templatePath, 0755)
+
+	var ids []string
+	for i := 0; i < versions.MaxSnapshots+2; i++ {
+		v, err := versions.Snapshot(root, "api", templatePath, nil)
+		if err != nil {
+			t.Fatalf("Snapshot() failed: %v", err)
+		}
+		ids = append(ids, v.ID)
+	}
+
+	list, err := versions.List(root, "api")
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(list) != versions.MaxSnapshots {
+		t.Fatalf("List() = %d versions, want %d", len(list), versions.MaxSnapshots)
+	}
+	if list[0].ID != ids[len(ids)-1] {
+		t.Errorf("newest version = %s, want %s", list[0].ID, ids[len(ids)-1])
+	}
+	if found, _ := versions.Find(root, "api", ids[0]); found != nil {
+		t.Errorf("oldest snapshot %s was not pruned", ids[0])
+	}
+}
+
+func TestVersionsFetchTag(t *testing.T) {
+	url, first := newGitRepo(t)
+	root := t.TempDir()
+
+	v, err := versions.FetchTag(root, "api", url, "v1", io.Discard)
+	if err != nil {
+		t.Fatalf("FetchTag() failed: %v", err)
+	}
+	if v.Kind != versions.KindTag || v.Commit != first {
+		t.Errorf("FetchTag() = %+v, want tag at %s", v, first)
+	}
+	data, _ := os.ReadFile(filepath.Join(v.Path, "VERSION"))
+	if string(data) != "v1" {
+		t.Errorf("tag checkout VERSION = %q, want v1", data)
+	}
+	if _, err := os.Stat(filepath.Join(v.Path, ".git")); !os.IsNotExist(err) {
+		t.Error("tag copy still contains .git")
+	}
+
+	// A stored tag is reused without fetching
+	again, err := versions.FetchTag(root, "api", "file:///does/not/exist", "v1", io.Discard)
+	if err != nil || again.Path != v.Path {
+		t.Errorf("second FetchTag() = %+v, %v; want stored copy", again, err)
+	}
+}